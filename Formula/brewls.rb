@@ -8,10 +8,11 @@ class Brewls < Formula
   depends_on "go" => :build
 
   def install
-    system "go", "build", "-o", bin/"brewls", "./cmd/brewls"
+    system "go", "build", "-ldflags", "-X brewls/internal/cli.Version=v#{version}", "-o", bin/"brewls", "./cmd/brewls"
   end
 
   test do
-    system "#{bin}/brewls", "-h"
+    assert_match "Usage:", shell_output("#{bin}/brewls --help")
+    assert_match version.to_s, shell_output("#{bin}/brewls --version")
   end
 end
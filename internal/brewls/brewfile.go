@@ -0,0 +1,244 @@
+package brewls
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Taps that brew bundle knows without a tap line.
+var officialTaps = []string{"", "homebrew/core", "homebrew/cask"}
+
+// BrewfileRenderer writes a Brewfile for brew bundle that reinstalls the root
+// packages of the view, with tap lines for third-party taps. Dependencies are
+// left out; brew installs them again with the packages that need them.
+type BrewfileRenderer struct {
+	// AllRequested also keeps formulae installed on request that other
+	// packages depend on, which would otherwise come back only as dependencies.
+	AllRequested bool
+	// Comments ends each line with the installed packages it pulls in.
+	Comments bool
+}
+
+// Render implements Renderer.
+func (r BrewfileRenderer) Render(view *View, writer io.Writer) error {
+	info := view.Installation
+	if info == nil {
+		info = &BrewInfo{}
+	}
+	deps := InstalledDependencies(info)
+
+	var taps, lines []string
+	add := func(kind, name, tap string, pulls []string) {
+		if !slices.Contains(officialTaps, tap) && !slices.Contains(taps, tap) {
+			taps = append(taps, tap)
+		}
+		line := fmt.Sprintf("%s %q", kind, name)
+		if r.Comments && len(pulls) > 0 {
+			line += " # pulls in " + strings.Join(pulls, ", ")
+		}
+		lines = append(lines, line)
+	}
+	for _, row := range view.Formulae {
+		if !row.IsRoot && !(r.AllRequested && row.Formula != nil && row.Formula.InstalledOnRequest()) {
+			continue
+		}
+		name := row.Name
+		if !slices.Contains(officialTaps, row.Tap) && row.FullName != "" {
+			name = row.FullName // e.g. hashicorp/tap/terraform
+		}
+		add("brew", name, row.Tap, TransitiveClosure(deps, []string{row.Name}))
+	}
+	for _, row := range view.Casks {
+		if row.IsRoot || r.AllRequested {
+			add("cask", row.Name, row.Tap, nil)
+		}
+	}
+	slices.Sort(taps)
+
+	var b strings.Builder
+	b.WriteString("# Brewfile written by brewls export brewfile; install with brew bundle.\n")
+	for _, tap := range taps {
+		fmt.Fprintf(&b, "tap %q\n", tap)
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+// BrewfileEntry is one line of a Brewfile, such as `brew "wget", args: ["HEAD"]`.
+type BrewfileEntry struct {
+	Kind    string `json:"kind"`              // tap, brew, cask, or another brew bundle type such as mas
+	Name    string `json:"name"`              // as written, e.g. "hashicorp/tap/terraform"; "" for cask_args
+	Options string `json:"options,omitempty"` // the rest of the line after the name, e.g. `args: ["HEAD"]`
+	Line    int    `json:"line"`
+}
+
+func (e BrewfileEntry) String() string {
+	if e.Name == "" && slices.Contains(brewfileArgumentDirectives, e.Kind) {
+		return e.Kind + " " + e.Options
+	}
+	s := fmt.Sprintf("%s %q", e.Kind, e.Name)
+	if e.Options != "" {
+		s += ", " + e.Options
+	}
+	return s
+}
+
+// brewfileArgumentDirectives take options but no name, e.g.
+// `cask_args appdir: "~/Applications"`.
+var brewfileArgumentDirectives = []string{"cask_args"}
+
+// ParseBrewfile reads the entries of a Brewfile. Each entry is a type followed
+// by a quoted name and optional options, or a directive such as cask_args
+// followed by options alone; blank lines and # comments are skipped. Ruby
+// beyond that, such as conditionals, is rejected with the line.
+func ParseBrewfile(reader io.Reader) ([]BrewfileEntry, error) {
+	var entries []BrewfileEntry
+	scanner := bufio.NewScanner(reader)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripBrewfileComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if m := brewfileArgumentsRE.FindStringSubmatch(line); m != nil && slices.Contains(brewfileArgumentDirectives, m[1]) {
+			entries = append(entries, BrewfileEntry{Kind: m[1], Options: strings.TrimSpace(m[2]), Line: n})
+			continue
+		}
+		m := brewfileEntryRE.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected an entry such as brew \"name\", got %q", n, line)
+		}
+		name := m[2]
+		if m[3] != "" {
+			name = m[3]
+		}
+		options := strings.TrimSpace(m[4])
+		if options != "" {
+			if !strings.HasPrefix(options, ",") {
+				return nil, fmt.Errorf("line %d: expected a comma before the options of %s %q", n, m[1], name)
+			}
+			options = strings.TrimSpace(options[1:])
+		}
+		entries = append(entries, BrewfileEntry{Kind: m[1], Name: name, Options: options, Line: n})
+	}
+	return entries, scanner.Err()
+}
+
+var (
+	brewfileEntryRE     = regexp.MustCompile(`^([a-z_]+)\s*\(?\s*(?:"([^"]*)"|'([^']*)')(.*?)\)?$`)
+	brewfileArgumentsRE = regexp.MustCompile(`^([a-z_]+)\s*\(?\s*([a-z_]+:.*?)\)?$`)
+)
+
+// stripBrewfileComment removes a # comment that is not inside a string.
+func stripBrewfileComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// BrewfileDrift is the difference between a Brewfile and the installed packages.
+type BrewfileDrift struct {
+	// Missing lists brew and cask entries that are not installed.
+	Missing []BrewfileEntry `json:"missing"`
+	// Extra lists root packages that no entry names, as brew or cask entries.
+	Extra []BrewfileEntry `json:"extra"`
+	// DependencyOnly lists entries whose formula is installed, but only as a
+	// dependency of another package, so uninstalling that would remove it.
+	DependencyOnly []BrewfileEntry `json:"dependency_only"`
+	// Skipped lists entries brewls cannot check, such as mas, whalebrew and
+	// vscode. They do not count as drift.
+	Skipped []BrewfileEntry `json:"skipped"`
+}
+
+// InSync reports whether the Brewfile matches the installation.
+func (d BrewfileDrift) InSync() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.DependencyOnly) == 0
+}
+
+// CheckBrewfile compares Brewfile entries with info, whose reverse dependency
+// graph must have been built. Entries match a formula by name or full name
+// (tap/name) and a cask by token, with or without its tap. Tap and cask_args
+// lines need no check, and other entry types, which install from outside
+// Homebrew, are listed as skipped.
+func CheckBrewfile(info *BrewInfo, entries []BrewfileEntry) BrewfileDrift {
+	drift := BrewfileDrift{Missing: []BrewfileEntry{}, Extra: []BrewfileEntry{}, DependencyOnly: []BrewfileEntry{}, Skipped: []BrewfileEntry{}}
+	listed := make(map[string]bool)
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case "brew":
+			i := slices.IndexFunc(info.Formulae, func(f Formula) bool { return f.Name == entry.Name || f.FullName == entry.Name })
+			if i < 0 {
+				drift.Missing = append(drift.Missing, entry)
+				continue
+			}
+			f := info.Formulae[i]
+			listed["brew "+f.Name] = true
+			if !f.InstalledOnRequest() {
+				drift.DependencyOnly = append(drift.DependencyOnly, entry)
+			}
+		case "cask":
+			i := slices.IndexFunc(info.Casks, func(c Cask) bool { return c.Token == entry.Name || c.Tap+"/"+c.Token == entry.Name })
+			if i < 0 {
+				drift.Missing = append(drift.Missing, entry)
+				continue
+			}
+			listed["cask "+info.Casks[i].Token] = true
+		case "tap", "cask_args":
+		default:
+			drift.Skipped = append(drift.Skipped, entry)
+		}
+	}
+
+	for _, f := range info.Formulae {
+		if f.IsRoot && !listed["brew "+f.Name] {
+			name := f.Name
+			if !slices.Contains(officialTaps, f.Tap) && f.FullName != "" {
+				name = f.FullName
+			}
+			drift.Extra = append(drift.Extra, BrewfileEntry{Kind: "brew", Name: name})
+		}
+	}
+	for _, c := range info.Casks {
+		if c.IsRoot && !listed["cask "+c.Token] {
+			drift.Extra = append(drift.Extra, BrewfileEntry{Kind: "cask", Name: c.Token})
+		}
+	}
+	return drift
+}
+
+// WriteBrewfileDrift prints drift as a diff against the Brewfile: "-" for
+// entries that are not installed, "+" for roots the Brewfile lacks and "~" for
+// entries installed only as dependencies.
+func WriteBrewfileDrift(writer io.Writer, drift BrewfileDrift) error {
+	var b strings.Builder
+	for _, e := range drift.Missing {
+		fmt.Fprintf(&b, "- %s  (line %d: not installed)\n", e, e.Line)
+	}
+	for _, e := range drift.Extra {
+		fmt.Fprintf(&b, "+ %s  (installed root, not in the Brewfile)\n", e)
+	}
+	for _, e := range drift.DependencyOnly {
+		fmt.Fprintf(&b, "~ %s  (line %d: installed only as a dependency)\n", e, e.Line)
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
@@ -0,0 +1,156 @@
+package brewls_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func brewfileTestInfo() *brewls.BrewInfo {
+	info := statsTestInfo()
+	info.Formulae = append(info.Formulae, brewls.Formula{
+		Name: "terraform", FullName: "hashicorp/tap/terraform", Tap: "hashicorp/tap",
+		Installed: []brewls.Installed{{Version: "1.8.0", InstalledOnRequest: true}},
+	})
+	info.Formulae[5].Installed[0].InstalledOnRequest = true // pcre2, also needed by git
+	info.Casks = append(info.Casks, brewls.Cask{Token: "font-fira-code", Tap: "homebrew/cask-fonts", Installed: "6.2"})
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func TestBrewfileRenderer(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer brewls.BrewfileRenderer
+		want     string
+	}{
+		{"roots", brewls.BrewfileRenderer{}, `# Brewfile written by brewls export brewfile; install with brew bundle.
+tap "hashicorp/tap"
+tap "homebrew/cask-fonts"
+brew "git"
+brew "wget"
+brew "curl"
+brew "hashicorp/tap/terraform"
+cask "firefox"
+cask "font-fira-code"
+`},
+		{"all requested with comments", brewls.BrewfileRenderer{AllRequested: true, Comments: true}, `# Brewfile written by brewls export brewfile; install with brew bundle.
+tap "hashicorp/tap"
+tap "homebrew/cask-fonts"
+brew "git" # pulls in gettext, pcre2
+brew "wget" # pulls in ca-certificates, gettext, openssl@3
+brew "curl" # pulls in ca-certificates, openssl@3
+brew "pcre2"
+brew "hashicorp/tap/terraform"
+cask "firefox"
+cask "font-fira-code"
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.renderer.Render(brewls.NewView(brewfileTestInfo(), brewls.ViewOptions{}), &buf); err != nil {
+				t.Fatalf("Render returned error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestParseBrewfile(t *testing.T) {
+	brewfile := `# Team Brewfile
+tap "hashicorp/tap"
+brew "git"
+brew "hashicorp/tap/terraform", link: true # pinned by ops
+brew 'wget', args: ["with-#-in-string"]
+cask("firefox")
+mas "Xcode", id: 497799835
+cask_args appdir: "~/Applications", require_sha: true
+`
+	entries, err := brewls.ParseBrewfile(strings.NewReader(brewfile))
+	if err != nil {
+		t.Fatalf("ParseBrewfile returned error: %v", err)
+	}
+	expected := []brewls.BrewfileEntry{
+		{Kind: "tap", Name: "hashicorp/tap", Line: 2},
+		{Kind: "brew", Name: "git", Line: 3},
+		{Kind: "brew", Name: "hashicorp/tap/terraform", Options: "link: true", Line: 4},
+		{Kind: "brew", Name: "wget", Options: `args: ["with-#-in-string"]`, Line: 5},
+		{Kind: "cask", Name: "firefox", Line: 6},
+		{Kind: "mas", Name: "Xcode", Options: "id: 497799835", Line: 7},
+		{Kind: "cask_args", Options: `appdir: "~/Applications", require_sha: true`, Line: 8},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+
+	if got := entries[6].String(); got != `cask_args appdir: "~/Applications", require_sha: true` {
+		t.Errorf("Unexpected cask_args entry %q", got)
+	}
+
+	for _, bad := range []string{"brew git", `brew "git" if OS.mac?`, "brew args: []"} {
+		if _, err := brewls.ParseBrewfile(strings.NewReader("\n" + bad)); err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+			t.Errorf("Expected an error on line 2 for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestCheckBrewfile(t *testing.T) {
+	entries, err := brewls.ParseBrewfile(strings.NewReader(`tap "hashicorp/tap"
+cask_args appdir: "~/Applications"
+brew "git"
+brew "hashicorp/tap/terraform"
+brew "gettext"
+brew "htop"
+cask "homebrew/cask/firefox"
+mas "Xcode", id: 497799835
+vscode "golang.go"
+whalebrew "whalebrew/wget"
+`))
+	if err != nil {
+		t.Fatalf("ParseBrewfile returned error: %v", err)
+	}
+	info := brewfileTestInfo()
+	info.Casks[0].Tap = "homebrew/cask"
+	drift := brewls.CheckBrewfile(info, entries)
+
+	expected := brewls.BrewfileDrift{
+		Missing: []brewls.BrewfileEntry{{Kind: "brew", Name: "htop", Line: 6}},
+		Extra: []brewls.BrewfileEntry{
+			{Kind: "brew", Name: "wget"},
+			{Kind: "brew", Name: "curl"},
+			{Kind: "cask", Name: "font-fira-code"},
+		},
+		DependencyOnly: []brewls.BrewfileEntry{{Kind: "brew", Name: "gettext", Line: 5}},
+		Skipped: []brewls.BrewfileEntry{
+			{Kind: "mas", Name: "Xcode", Options: "id: 497799835", Line: 8},
+			{Kind: "vscode", Name: "golang.go", Line: 9},
+			{Kind: "whalebrew", Name: "whalebrew/wget", Line: 10},
+		},
+	}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, drift)
+	}
+	if drift.InSync() {
+		t.Error("Expected drift")
+	}
+
+	var buf bytes.Buffer
+	if err := brewls.WriteBrewfileDrift(&buf, drift); err != nil {
+		t.Fatalf("WriteBrewfileDrift returned error: %v", err)
+	}
+	want := `- brew "htop"  (line 6: not installed)
++ brew "wget"  (installed root, not in the Brewfile)
++ brew "curl"  (installed root, not in the Brewfile)
++ cask "font-fira-code"  (installed root, not in the Brewfile)
+~ brew "gettext"  (line 5: installed only as a dependency)
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, buf.String())
+	}
+}
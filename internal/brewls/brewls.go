@@ -7,10 +7,7 @@ import (
 	"io"
 	"os/exec"
 	"sort"
-	"strconv"
 	"strings"
-
-	"github.com/jedib0t/go-pretty/v6/table" // New import for go-pretty
 )
 
 // BrewInfo represents the top-level structure of the JSON output from brew info --json=v2
@@ -22,10 +19,70 @@ type BrewInfo struct {
 // Formula represents a Homebrew formula
 type Formula struct {
 	Name         string      `json:"name"`
+	FullName     string      `json:"full_name"`
+	Tap          string      `json:"tap"`
+	Desc         string      `json:"desc"`
+	Homepage     string      `json:"homepage"`
+	License      string      `json:"license"` // SPDX expression with lower-case operators, e.g. "MIT or Apache-2.0"
 	Installed    []Installed `json:"installed"`
 	Dependencies []string    `json:"dependencies"` // Build dependencies
+	Versions     Versions    `json:"versions"`     // Versions available from the tap
+	Revision     int         `json:"revision"`     // Rebuild counter of the current stable version
+	Outdated     bool        `json:"outdated"`
+	Deprecated   bool        `json:"deprecated"`
+	Disabled     bool        `json:"disabled"`
 	InstalledBy  []string    // New field: packages that depend on this one
 	IsRoot       bool        // New field: true if this is a top-level package (not depended on)
+	SizeBytes    int64       `json:"-"` // Disk usage of the installed keg, set by PopulateDiskUsage
+}
+
+// InstalledVersion returns the most recently installed version, or "" if none is installed.
+func (f Formula) InstalledVersion() string {
+	if len(f.Installed) == 0 {
+		return ""
+	}
+	return f.Installed[len(f.Installed)-1].Version
+}
+
+// LatestVersion returns the stable version brew would install, with its "_N"
+// revision suffix like installed versions have, or "" if it is not known.
+func (f Formula) LatestVersion() string {
+	if f.Versions.Stable == "" || f.Revision == 0 {
+		return f.Versions.Stable
+	}
+	return fmt.Sprintf("%s_%d", f.Versions.Stable, f.Revision)
+}
+
+// InstalledOnRequest reports whether the most recent install was requested by
+// the user rather than pulled in as a dependency.
+func (f Formula) InstalledOnRequest() bool {
+	return len(f.Installed) > 0 && f.Installed[len(f.Installed)-1].InstalledOnRequest
+}
+
+// IsOrphan reports whether the formula was installed only as a dependency and
+// nothing installed depends on it any more, the leftovers brew autoremove
+// would uninstall. The reverse dependency graph must have been built.
+func (f Formula) IsOrphan() bool {
+	return len(f.Installed) > 0 && !f.InstalledOnRequest() && len(f.InstalledBy) == 0
+}
+
+// AllDependencies combines build and runtime dependencies of the most recent install,
+// unique and sorted. Dependencies that are not installed are included.
+func (f Formula) AllDependencies() []string {
+	var dependencies []string
+	dependencies = append(dependencies, f.Dependencies...)
+	if len(f.Installed) > 0 {
+		for _, rd := range f.Installed[len(f.Installed)-1].RuntimeDependencies {
+			dependencies = append(dependencies, rd.FullName)
+		}
+	}
+	return UniqueAndSortStrings(dependencies) // Ensure unique and sorted dependencies
+}
+
+// Versions lists the versions of a formula its tap provides.
+type Versions struct {
+	Stable string `json:"stable"`
+	Head   string `json:"head"`
 }
 
 // Installed represents an installed version of a formula
@@ -33,6 +90,7 @@ type Installed struct {
 	Version             string              `json:"version"`
 	RuntimeDependencies []RuntimeDependency `json:"runtime_dependencies"`
 	InstalledOnRequest  bool                `json:"installed_on_request"` // This field is crucial for identifying root packages
+	Time                int64               `json:"time"`                 // Unix timestamp of the install
 }
 
 // RuntimeDependency represents a runtime dependency of an installed formula
@@ -43,12 +101,20 @@ type RuntimeDependency struct {
 
 // Cask represents a Homebrew cask
 type Cask struct {
-	Token       string   `json:"token"`
-	Name        []string `json:"name"` // Display name, if available
-	Version     string   `json:"version"`
-	Installed   string   `json:"installed"` // This seems to represent the installed version for casks
-	InstalledBy []string // New field: packages that depend on this one (less common for casks)
-	IsRoot      bool     // New field: true if this is a top-level package
+	Token         string   `json:"token"`
+	Name          []string `json:"name"` // Display name, if available
+	Tap           string   `json:"tap"`
+	Desc          string   `json:"desc"`
+	Homepage      string   `json:"homepage"`
+	Version       string   `json:"version"`
+	Installed     string   `json:"installed"` // This seems to represent the installed version for casks
+	Outdated      bool     `json:"outdated"`
+	Deprecated    bool     `json:"deprecated"`
+	Disabled      bool     `json:"disabled"`
+	InstalledTime int64    `json:"installed_time"` // Unix timestamp of the install
+	InstalledBy   []string // New field: packages that depend on this one (less common for casks)
+	IsRoot        bool     // New field: true if this is a top-level package
+	SizeBytes     int64    `json:"-"` // Disk usage of the Caskroom entry, set by PopulateDiskUsage
 	// Homebrew cask info often just lists depends_on for macOS versions or other casks/formulae,
 	// direct dependencies are not as clear-cut as for formulae.
 	// For simplicity, we'll primarily rely on formulae dependencies for now.
@@ -62,15 +128,20 @@ var ExecCommand = exec.Command // Exported for testing
 // lookPath is a global variable to allow mocking os/exec.LookPath in tests.
 var LookPath = exec.LookPath // Exported for testing
 
+// BrewPath is the brew executable brewls runs: a name looked up in PATH, or a path
+// such as /opt/homebrew/bin/brew when several Homebrew installations exist.
+var BrewPath = "brew"
+
 // ExecuteBrewInfoCommand runs the brew command and returns its JSON output as a string.
 func ExecuteBrewInfoCommand() (string, error) {
 	// Check if "brew" command is available
-	_, err := LookPath("brew")
+	_, err := LookPath(BrewPath)
 	if err != nil {
 		return "", fmt.Errorf("Homebrew 'brew' command not found in PATH: %w. Please ensure Homebrew is installed and configured correctly.", err)
 	}
 
-	cmdString := "brew list | xargs brew info --json=v2"
+	brew := shellQuote(BrewPath)
+	cmdString := brew + " list | xargs " + brew + " info --json=v2"
 	cmd := ExecCommand("bash", "-c", cmdString) // Use exported ExecCommand
 
 	var stdoutBuf, stderrBuf bytes.Buffer
@@ -85,6 +156,18 @@ func ExecuteBrewInfoCommand() (string, error) {
 	return stdoutBuf.String(), nil
 }
 
+// shellQuote returns word unchanged when it is safe to use in a bash command,
+// or wrapped in single quotes otherwise.
+func shellQuote(word string) string {
+	safe := word != "" && strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r))
+	}) < 0
+	if safe {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
 // ParseBrewInfoJSON unmarshals the JSON string into a BrewInfo struct.
 func ParseBrewInfoJSON(jsonInput string) (*BrewInfo, error) {
 	var brewInfo BrewInfo
@@ -112,16 +195,7 @@ func BuildReverseDependencyGraph(info *BrewInfo) {
 
 	// Process Formulae dependencies
 	for _, f := range info.Formulae {
-		// Combine build and runtime dependencies
-		var dependencies []string
-		dependencies = append(dependencies, f.Dependencies...)
-		if len(f.Installed) > 0 {
-			for _, rd := range f.Installed[len(f.Installed)-1].RuntimeDependencies {
-				dependencies = append(dependencies, rd.FullName)
-			}
-		}
-
-		for _, dep := range UniqueAndSortStrings(dependencies) { // Ensure unique and sorted dependencies
+		for _, dep := range f.AllDependencies() {
 			// Only consider dependencies that are actually installed
 			if _, ok := allInstalledPackages[dep]; ok {
 				installedByMap[dep] = append(installedByMap[dep], f.Name)
@@ -160,92 +234,10 @@ func BuildReverseDependencyGraph(info *BrewInfo) {
 }
 
 // FormatBrewOutput generates the formatted tabular output for formulae and casks.
-// It now accepts an io.Writer interface, making it more testable.
-func FormatBrewOutput(brewInfo *BrewInfo, writer io.Writer) {
-	showInstalledByCount := IsFeatureEnabled("installed-by-count")
-	formulae := brewInfo.Formulae
-	casks := brewInfo.Casks
-	if FeatureEnabled(featureSortOutput) {
-		formulae = append([]Formula(nil), brewInfo.Formulae...)
-		sort.Slice(formulae, func(i, j int) bool {
-			return formulae[i].Name < formulae[j].Name
-		})
-
-		casks = append([]Cask(nil), brewInfo.Casks...)
-		sort.Slice(casks, func(i, j int) bool {
-			return casks[i].Token < casks[j].Token
-		})
-	}
-
-	// --- Process and Format Formulae ---
-	fmt.Fprintln(writer, "\n--- Homebrew Formulae ---")
-
-	// Create a new go-pretty table writer
-	formulaeTable := table.NewWriter()
-	formulaeTable.SetOutputMirror(writer) // Set the output writer
-	// Changed header from "Dependencies" to "Installed By"
-	if showInstalledByCount {
-		formulaeTable.AppendHeader(table.Row{"Name", "Version", "Installed By", "Installed By Count"})
-	} else {
-		formulaeTable.AppendHeader(table.Row{"Name", "Version", "Installed By"})
-	}
-
-	for _, formula := range formulae {
-		installedVersion := "N/A"
-
-		if len(formula.Installed) > 0 {
-			installedVersion = formula.Installed[len(formula.Installed)-1].Version
-		}
-
-		// Determine display name for formulae
-		displayName := formula.Name
-		if formula.IsRoot {
-			displayName += " *"
-		}
-
-		row := table.Row{
-			displayName,
-			installedVersion,
-			strings.Join(formula.InstalledBy, ", "),
-		}
-		if showInstalledByCount {
-			row = append(row, strconv.Itoa(len(formula.InstalledBy)))
-		}
-		formulaeTable.AppendRow(row)
-	}
-	formulaeTable.Render()
-
-	// --- Process and Format Casks ---
-	fmt.Fprintln(writer, "\n--- Homebrew Casks ---")
-	casksTable := table.NewWriter()
-	casksTable.SetOutputMirror(writer) // Set the output writer
-	// Changed header from "Dependencies" to "Installed By"
-	if showInstalledByCount {
-		casksTable.AppendHeader(table.Row{"Name", "Version", "Installed By", "Installed By Count"})
-	} else {
-		casksTable.AppendHeader(table.Row{"Name", "Version", "Installed By"})
-	}
-
-	for _, cask := range casks {
-		displayName := cask.Token
-		if len(cask.Name) > 0 {
-			displayName = cask.Name[0]
-		}
-		if cask.IsRoot {
-			displayName += " *"
-		}
-
-		row := table.Row{
-			displayName,
-			cask.Installed,
-			strings.Join(cask.InstalledBy, ", "),
-		}
-		if showInstalledByCount {
-			row = append(row, strconv.Itoa(len(cask.InstalledBy)))
-		}
-		casksTable.AppendRow(row)
-	}
-	casksTable.Render()
+// It now accepts an io.Writer interface, making it more testable, and returns
+// any error writing to it.
+func FormatBrewOutput(brewInfo *BrewInfo, writer io.Writer) error {
+	return TableRenderer{}.Render(NewView(brewInfo, DefaultViewOptions()), writer)
 }
 
 // UniqueAndSortStrings is a helper function to remove duplicates and sort strings.
@@ -478,7 +478,9 @@ func TestFormatBrewOutput(t *testing.T) {
 			brewls.BuildReverseDependencyGraph(&brewInfoCopy)
 
 			var buf bytes.Buffer
-			brewls.FormatBrewOutput(&brewInfoCopy, &buf) // Pass bytes.Buffer directly
+			if err := brewls.FormatBrewOutput(&brewInfoCopy, &buf); err != nil { // Pass bytes.Buffer directly
+				t.Fatalf("FormatBrewOutput returned error: %v", err)
+			}
 
 			normalizedExpected := strings.TrimSpace(strings.ReplaceAll(tt.expectedOutput, "\r\n", "\n"))
 			normalizedActual := strings.TrimSpace(strings.ReplaceAll(buf.String(), "\r\n", "\n"))
@@ -490,6 +492,60 @@ func TestFormatBrewOutput(t *testing.T) {
 	}
 }
 
+// failingWriter accepts limit bytes, then fails every write.
+type failingWriter struct{ limit int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestFormatBrewOutputReturnsWriteErrors(t *testing.T) {
+	info := &brewls.BrewInfo{Formulae: []brewls.Formula{{Name: "git", Installed: []brewls.Installed{{Version: "2.44.0"}}}}}
+	brewls.BuildReverseDependencyGraph(info)
+	// Fail on the section title, and on the table that follows it.
+	for _, limit := range []int{0, len("\n--- Homebrew Formulae ---\n")} {
+		if err := brewls.FormatBrewOutput(info, &failingWriter{limit: limit}); err == nil || err.Error() != "disk full" {
+			t.Errorf("Expected the write error after %d bytes, got %v", limit, err)
+		}
+	}
+}
+
+func TestExecuteBrewInfoCommandUsesBrewPath(t *testing.T) {
+	oldExecCommand, oldLookPath, oldBrewPath := brewls.ExecCommand, brewls.LookPath, brewls.BrewPath
+	defer func() {
+		brewls.ExecCommand, brewls.LookPath, brewls.BrewPath = oldExecCommand, oldLookPath, oldBrewPath
+	}()
+
+	var lookedUp string
+	var shellCommand []string
+	brewls.BrewPath = "/opt/my brew/bin/brew"
+	brewls.LookPath = func(file string) (string, error) {
+		lookedUp = file
+		return file, nil
+	}
+	brewls.ExecCommand = func(command string, args ...string) *exec.Cmd {
+		shellCommand = append([]string{command}, args...)
+		return exec.Command("true")
+	}
+
+	if _, err := brewls.ExecuteBrewInfoCommand(); err != nil {
+		t.Fatalf("ExecuteBrewInfoCommand returned error: %v", err)
+	}
+	if lookedUp != brewls.BrewPath {
+		t.Errorf("Expected LookPath(%q), got %q", brewls.BrewPath, lookedUp)
+	}
+	expected := []string{"bash", "-c", "'/opt/my brew/bin/brew' list | xargs '/opt/my brew/bin/brew' info --json=v2"}
+	if !reflect.DeepEqual(shellCommand, expected) {
+		t.Errorf("Expected command %q, got %q", expected, shellCommand)
+	}
+}
+
 func TestFeatureEnabled(t *testing.T) {
 	original := os.Getenv("BREWLS_FEATURES")
 	t.Cleanup(func() {
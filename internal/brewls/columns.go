@@ -0,0 +1,299 @@
+package brewls
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"brewls/pkg/version"
+)
+
+// ColumnsEnv overrides the default column set with a comma-separated list of column keys.
+const ColumnsEnv = "BREWLS_COLUMNS"
+
+// ColumnKind is the type of value a column holds. Sorting and filtering use it
+// to compare values; renderers use it to pick a default alignment.
+type ColumnKind int
+
+// Column kinds. Value functions return string, int64, bool, []string and time.Time respectively.
+const (
+	KindString ColumnKind = iota
+	KindNumber
+	KindBool
+	KindList
+	KindTime
+)
+
+func (k ColumnKind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	case KindList:
+		return "list"
+	case KindTime:
+		return "time"
+	default:
+		return fmt.Sprintf("ColumnKind(%d)", int(k))
+	}
+}
+
+// Alignment is the horizontal alignment of a column in table output.
+type Alignment int
+
+// Column alignments.
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+)
+
+// Column describes one selectable column: how to extract its value from a Row and how to show it.
+type Column struct {
+	Key     string     // identifier used by --columns, e.g. "installed_by"
+	Aliases []string   // alternative keys accepted on the command line
+	Header  string     // table header, e.g. "Installed By"
+	Kind    ColumnKind // type returned by Value
+	Align   Alignment  // alignment in table output
+	Width   int        // maximum cell width in table output, where longer lists end in "+N more"; 0 means unlimited
+
+	// Value returns the typed value used for sorting and filtering.
+	Value func(Row) any
+	// Format renders the cell text. When nil, the text is derived from Value.
+	Format func(row Row, listSeparator string) string
+	// Compare orders two rows for sorting. When nil, their Values are compared.
+	Compare func(a, b Row) int
+}
+
+// compare returns -1, 0 or 1 ordering a before, with or after b by this column.
+func (c Column) compare(a, b Row) int {
+	if c.Compare != nil {
+		return c.Compare(a, b)
+	}
+	return compareValues(c.Value(a), c.Value(b))
+}
+
+// Text returns the cell text for row.
+func (c Column) Text(row Row, listSeparator string) string {
+	if c.Format != nil {
+		return c.Format(row, listSeparator)
+	}
+	switch v := c.Value(row).(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return ""
+	case []string:
+		return strings.Join(v, listSeparator)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Local().Format("2006-01-02 15:04")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// DefaultColumnKeys is the column set used when none is configured.
+var DefaultColumnKeys = []string{"name", "version", "installed_by"}
+
+var (
+	columnsMu sync.RWMutex
+	columns   = map[string]Column{}
+	// columnOrder keeps registration order for listings such as --help.
+	columnOrder []string
+)
+
+func init() {
+	for _, c := range builtinColumns() {
+		RegisterColumn(c)
+	}
+}
+
+func builtinColumns() []Column {
+	return []Column{
+		{
+			Key: "name", Header: "Name", Kind: KindString,
+			Value:  func(r Row) any { return r.Name },
+			Format: func(r Row, _ string) string { return r.DisplayName },
+		},
+		{
+			Key: "version", Header: "Version", Kind: KindString,
+			Value: func(r Row) any { return r.Version },
+			// Versions sort the way Homebrew orders them, so 2.10 comes after 2.9.
+			Compare: func(a, b Row) int { return version.Compare(a.Version, b.Version) },
+			Format: func(r Row, _ string) string {
+				if r.Version == "" && r.Type == PackageTypeFormula {
+					return "N/A"
+				}
+				return r.Version
+			},
+		},
+		{
+			// Changed header from "Dependencies" to "Installed By"
+			Key: "installed_by", Header: "Installed By", Kind: KindList,
+			Value: func(r Row) any { return r.InstalledBy },
+		},
+		{
+			Key: "installed_by_count", Aliases: []string{"count"}, Header: "Installed By Count", Kind: KindNumber, Align: AlignRight,
+			Value: func(r Row) any { return int64(len(r.InstalledBy)) },
+		},
+		{
+			Key: "type", Header: "Type", Kind: KindString,
+			Value: func(r Row) any { return r.Type },
+		},
+		{
+			Key: "root", Header: "Root", Kind: KindBool,
+			Value: func(r Row) any { return r.IsRoot },
+		},
+		{
+			Key: "orphan", Header: "Orphan", Kind: KindBool,
+			Value: func(r Row) any { return r.Orphan },
+		},
+		{
+			Key: "full_name", Header: "Full Name", Kind: KindString,
+			Value: func(r Row) any { return r.FullName },
+		},
+		{
+			Key: "tap", Header: "Tap", Kind: KindString,
+			Value: func(r Row) any { return r.Tap },
+		},
+		{
+			Key: "desc", Header: "Description", Kind: KindString, Width: 60,
+			Value: func(r Row) any { return r.Desc },
+		},
+		{
+			Key: "homepage", Header: "Homepage", Kind: KindString,
+			Value: func(r Row) any { return r.Homepage },
+		},
+		{
+			Key: "license", Header: "License", Kind: KindString,
+			Value: func(r Row) any { return r.License },
+		},
+		{
+			Key: "outdated", Header: "Outdated", Kind: KindBool,
+			Value: func(r Row) any { return r.Outdated },
+		},
+		{
+			Key: "deprecated", Header: "Deprecated", Kind: KindBool,
+			Value: func(r Row) any { return r.Deprecated },
+		},
+		{
+			Key: "disabled", Header: "Disabled", Kind: KindBool,
+			Value: func(r Row) any { return r.Disabled },
+		},
+		{
+			Key: "installed_at", Header: "Installed At", Kind: KindTime,
+			Value: func(r Row) any { return r.InstalledAt },
+		},
+		{
+			Key: "size", Header: "Size", Kind: KindNumber, Align: AlignRight,
+			Value: func(r Row) any { return r.SizeBytes },
+			Format: func(r Row, _ string) string {
+				if r.SizeBytes == 0 {
+					return ""
+				}
+				return HumanizeBytes(r.SizeBytes)
+			},
+		},
+	}
+}
+
+// RegisterColumn adds a column to the registry, or replaces the column with the same key.
+func RegisterColumn(c Column) {
+	c.Key = normalizeColumnKey(c.Key)
+	if c.Key == "" || c.Value == nil {
+		panic("brewls: RegisterColumn requires a key and a Value function")
+	}
+	if c.Header == "" {
+		c.Header = c.Key
+	}
+
+	columnsMu.Lock()
+	defer columnsMu.Unlock()
+	if _, exists := columns[c.Key]; !exists {
+		columnOrder = append(columnOrder, c.Key)
+	}
+	columns[c.Key] = c
+}
+
+// LookupColumn returns the column registered under key or one of its aliases.
+func LookupColumn(key string) (Column, bool) {
+	key = normalizeColumnKey(key)
+
+	columnsMu.RLock()
+	defer columnsMu.RUnlock()
+	if c, ok := columns[key]; ok {
+		return c, true
+	}
+	for _, name := range columnOrder {
+		for _, alias := range columns[name].Aliases {
+			if alias == key {
+				return columns[name], true
+			}
+		}
+	}
+	return Column{}, false
+}
+
+// ColumnKeys returns the registered column keys in registration order.
+func ColumnKeys() []string {
+	columnsMu.RLock()
+	defer columnsMu.RUnlock()
+	return append([]string(nil), columnOrder...)
+}
+
+// ResolveColumns looks up each key, reporting the first unknown one.
+func ResolveColumns(keys []string) ([]Column, error) {
+	result := make([]Column, 0, len(keys))
+	for _, key := range keys {
+		c, ok := LookupColumn(key)
+		if !ok {
+			known := ColumnKeys()
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown column %q (available: %s)", key, strings.Join(known, ", "))
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// ParseColumnList splits a comma-separated column list, dropping blanks.
+func ParseColumnList(raw string) []string {
+	var keys []string
+	for _, item := range strings.Split(raw, ",") {
+		if key := normalizeColumnKey(item); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// DefaultColumns returns the configured default column keys: ColumnsEnv when set,
+// otherwise DefaultColumnKeys plus installed_by_count when that feature flag is on.
+func DefaultColumns() []string {
+	if keys := ParseColumnList(os.Getenv(ColumnsEnv)); len(keys) > 0 {
+		return keys
+	}
+	keys := append([]string(nil), DefaultColumnKeys...)
+	if IsFeatureEnabled(featureInstalledByCount) {
+		keys = append(keys, "installed_by_count")
+	}
+	return keys
+}
+
+func normalizeColumnKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
@@ -0,0 +1,130 @@
+package brewls_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func TestResolveColumns(t *testing.T) {
+	columns, err := brewls.ResolveColumns([]string{"name", "count", "TAP"})
+	if err != nil {
+		t.Fatalf("ResolveColumns returned error: %v", err)
+	}
+	var keys []string
+	for _, c := range columns {
+		keys = append(keys, c.Key)
+	}
+	if want := []string{"name", "installed_by_count", "tap"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected keys %v, got %v", want, keys)
+	}
+
+	_, err = brewls.ResolveColumns([]string{"name", "colour"})
+	if err == nil || !strings.Contains(err.Error(), `unknown column "colour"`) {
+		t.Errorf("Expected unknown column error, got %v", err)
+	}
+}
+
+func TestParseColumnList(t *testing.T) {
+	got := brewls.ParseColumnList(" Name, ,version,installed_by ")
+	if want := []string{"name", "version", "installed_by"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestDefaultColumns(t *testing.T) {
+	t.Setenv(brewls.ColumnsEnv, "")
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	if got, want := brewls.DefaultColumns(), []string{"name", "version", "installed_by"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	t.Setenv(brewls.FeatureFlagsEnv, "installed-by-count")
+	if got, want := brewls.DefaultColumns(), []string{"name", "version", "installed_by", "installed_by_count"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	t.Setenv(brewls.ColumnsEnv, "name,tap")
+	if got, want := brewls.DefaultColumns(), []string{"name", "tap"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestColumnText(t *testing.T) {
+	installedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.Local)
+	row := brewls.Row{
+		Type:        brewls.PackageTypeCask,
+		Name:        "firefox",
+		DisplayName: "Mozilla Firefox",
+		InstalledBy: []string{"a", "b"},
+		IsRoot:      true,
+		InstalledAt: installedAt,
+		SizeBytes:   3 * 1024 * 1024,
+	}
+
+	tests := map[string]string{
+		"name":               "Mozilla Firefox",
+		"version":            "",
+		"installed_by":       "a; b",
+		"installed_by_count": "2",
+		"root":               "yes",
+		"outdated":           "",
+		"installed_at":       "2024-03-01 12:30",
+		"size":               "3.0 MiB",
+		"type":               "cask",
+	}
+	for key, want := range tests {
+		c, ok := brewls.LookupColumn(key)
+		if !ok {
+			t.Fatalf("column %q is not registered", key)
+		}
+		if got := c.Text(row, "; "); got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+
+	if c, _ := brewls.LookupColumn("name"); c.Value(row) != "firefox" {
+		t.Errorf("Expected name value to be the cask token, got %v", c.Value(row))
+	}
+}
+
+func TestRegisterColumnAndTableColumns(t *testing.T) {
+	brewls.RegisterColumn(brewls.Column{
+		Key:    "shout",
+		Header: "Shout",
+		Value:  func(r brewls.Row) any { return strings.ToUpper(r.Name) },
+	})
+
+	columns := mustResolveColumns(t, "shout", "name", "count")
+	view := brewls.NewView(delimitedTestInfo(), brewls.ViewOptions{Columns: columns})
+
+	var buf bytes.Buffer
+	if err := (brewls.TableRenderer{}).Render(view, &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	expected := `
+--- Homebrew Formulae ---
++---------+---------+--------------------+
+| SHOUT   | NAME    | INSTALLED BY COUNT |
++---------+---------+--------------------+
+| ZLIB    | zlib    |                  2 |
+| CURL    | curl *  |                  0 |
+| LIBXML2 | libxml2 |                  0 |
++---------+---------+--------------------+
+
+--- Homebrew Casks ---
++--------+----------+--------------------+
+| SHOUT  | NAME     | INSTALLED BY COUNT |
++--------+----------+--------------------+
+| ITERM2 | iTerm2 * |                  0 |
++--------+----------+--------------------+
+`
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
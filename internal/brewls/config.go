@@ -0,0 +1,128 @@
+package brewls
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigEnv names a config file to read instead of the default location.
+const ConfigEnv = "BREWLS_CONFIG"
+
+// ConfigView holds listing defaults. The top level of the config file is one,
+// and each named view in Config.Views is another.
+type ConfigView struct {
+	Format        string          `json:"format,omitempty"`
+	Columns       []string        `json:"columns,omitempty"`
+	Sort          string          `json:"sort,omitempty"`
+	Filter        string          `json:"filter,omitempty"`
+	ListSeparator string          `json:"list_separator,omitempty"`
+	Color         string          `json:"color,omitempty"`   // auto, always or never
+	Style         string          `json:"style,omitempty"`   // One of TableStyles
+	Wide          *bool           `json:"wide,omitempty"`    // Do not fit tables to the terminal width; nil leaves it to the next layer
+	Widths        map[string]int  `json:"widths,omitempty"`  // Maximum table cell width by column key
+	Summary       *bool           `json:"summary,omitempty"` // End tables with a line of counts; nil leaves it to the next layer
+	Features      map[string]bool `json:"features,omitempty"`
+}
+
+// Config is the brewls config file, a JSON document such as:
+//
+//	{
+//	  "columns": ["name", "version", "installed_by", "tap"],
+//	  "sort": "name",
+//	  "style": "rounded",
+//	  "widths": {"installed_by": 40, "desc": 50},
+//	  "features": {"sort-output": true},
+//	  "brew_path": "/opt/homebrew/bin/brew",
+//	  "prefixes": ["/opt/homebrew", "/usr/local"],
+//	  "licenses": {"deny": ["AGPL-*", "SSPL-*"]},
+//	  "views": {
+//	    "audit": {"format": "csv", "columns": ["name", "version", "tap", "outdated"], "filter": "root"}
+//	  }
+//	}
+type Config struct {
+	ConfigView
+	BrewPath string                `json:"brew_path,omitempty"` // brew executable, see BrewPath
+	Prefixes []string              `json:"prefixes,omitempty"`  // Homebrew prefixes searched for disk usage
+	Licenses LicensePolicy         `json:"licenses,omitzero"`   // checked by brewls licenses
+	Views    map[string]ConfigView `json:"views,omitempty"`
+}
+
+// DefaultConfigPath returns the config file brewls reads: ConfigEnv when set,
+// otherwise brewls/config.json under $XDG_CONFIG_HOME or ~/.config.
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate config dir: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "brewls", "config.json"), nil
+}
+
+// LoadConfig reads and validates the config file at path. Unknown keys are
+// rejected so typos do not go unnoticed. A missing file is reported with an
+// error wrapping fs.ErrNotExist.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseConfig decodes and validates a config document.
+func ParseConfig(reader io.Reader) (*Config, error) {
+	var config Config
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	for name := range config.Views {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("view names must not be empty")
+		}
+	}
+	if err := config.Licenses.Validate(); err != nil {
+		return nil, fmt.Errorf("licenses: %w", err)
+	}
+	return &config, nil
+}
+
+// View returns the named view, or an error listing the available ones.
+func (c *Config) View(name string) (ConfigView, error) {
+	if view, ok := c.Views[name]; ok {
+		return view, nil
+	}
+	names := c.ViewNames()
+	if len(names) == 0 {
+		return ConfigView{}, fmt.Errorf("unknown view %q (no views are defined in the config file)", name)
+	}
+	return ConfigView{}, fmt.Errorf("unknown view %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// ViewNames returns the names of the configured views, sorted.
+func (c *Config) ViewNames() []string {
+	names := make([]string, 0, len(c.Views))
+	for name := range c.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
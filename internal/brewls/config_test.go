@@ -0,0 +1,95 @@
+package brewls_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "columns": ["name", "tap"],
+  "sort": "-count",
+  "features": {"sort-output": true},
+  "brew_path": "/opt/homebrew/bin/brew",
+  "prefixes": ["/opt/homebrew", "/usr/local"],
+  "views": {
+    "audit": {"format": "csv", "columns": ["name", "outdated"], "filter": "root"}
+  }
+}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := brewls.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	expected := &brewls.Config{
+		ConfigView: brewls.ConfigView{
+			Columns:  []string{"name", "tap"},
+			Sort:     "-count",
+			Features: map[string]bool{"sort-output": true},
+		},
+		BrewPath: "/opt/homebrew/bin/brew",
+		Prefixes: []string{"/opt/homebrew", "/usr/local"},
+		Views: map[string]brewls.ConfigView{
+			"audit": {Format: "csv", Columns: []string{"name", "outdated"}, Filter: "root"},
+		},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config)
+	}
+
+	if _, err := config.View("audit"); err != nil {
+		t.Errorf("Expected the audit view, got %v", err)
+	}
+	if _, err := config.View("nope"); err == nil || !strings.Contains(err.Error(), "available: audit") {
+		t.Errorf("Expected an error listing views, got %v", err)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := brewls.LoadConfig(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"colums": ["name"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := brewls.LoadConfig(path); err == nil || !strings.Contains(err.Error(), `unknown field "colums"`) {
+		t.Errorf("Expected unknown field error, got %v", err)
+	}
+
+	if config, err := brewls.ParseConfig(strings.NewReader("")); err != nil || config == nil {
+		t.Errorf("Expected an empty file to be an empty config, got %v", err)
+	}
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	t.Setenv(brewls.ConfigEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if got, _ := brewls.DefaultConfigPath(); got != filepath.Join("/tmp/xdg", "brewls", "config.json") {
+		t.Errorf("Unexpected XDG path %q", got)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/brew")
+	if got, _ := brewls.DefaultConfigPath(); got != filepath.Join("/home/brew", ".config", "brewls", "config.json") {
+		t.Errorf("Unexpected home path %q", got)
+	}
+
+	t.Setenv(brewls.ConfigEnv, "/etc/brewls.json")
+	if got, _ := brewls.DefaultConfigPath(); got != "/etc/brewls.json" {
+		t.Errorf("Expected %s to win, got %q", brewls.ConfigEnv, got)
+	}
+}
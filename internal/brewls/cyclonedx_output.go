@@ -0,0 +1,273 @@
+package brewls
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CycloneDXSpecVersion is the CycloneDX specification version of the documents
+// written by CycloneDXRenderer.
+const CycloneDXSpecVersion = "1.5"
+
+// CycloneDXRenderer writes the view as a CycloneDX JSON software bill of
+// materials: one component per formula and cask, and the dependencies between
+// them from the installed dependency graph.
+type CycloneDXRenderer struct {
+	Timestamp   time.Time // document creation time; the current time when zero
+	ToolVersion string    // brewls version recorded as the generating tool
+	Hostname    string    // the machine described, when known
+}
+
+// CycloneDXDocument is the subset of a CycloneDX 1.5 BOM that brewls writes.
+type CycloneDXDocument struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     CycloneDXMetadata     `json:"metadata"`
+	Components   []CycloneDXComponent  `json:"components"`
+	Dependencies []CycloneDXDependency `json:"dependencies"`
+}
+
+// CycloneDXMetadata describes when and by what a BOM was made, and for which machine.
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp"`
+	Tools     CycloneDXTools      `json:"tools"`
+	Component *CycloneDXComponent `json:"component,omitempty"`
+}
+
+// CycloneDXTools lists the tools that made a BOM.
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXComponent is a package in a BOM.
+type CycloneDXComponent struct {
+	Type               string                       `json:"type"`
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	Description        string                       `json:"description,omitempty"`
+	PURL               string                       `json:"purl,omitempty"`
+	Licenses           []CycloneDXLicenseChoice     `json:"licenses,omitempty"`
+	ExternalReferences []CycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Properties         []CycloneDXProperty          `json:"properties,omitempty"`
+}
+
+// CycloneDXLicenseChoice is either a single license or an SPDX expression.
+type CycloneDXLicenseChoice struct {
+	License    *CycloneDXLicense `json:"license,omitempty"`
+	Expression string            `json:"expression,omitempty"`
+}
+
+// CycloneDXLicense names a license by SPDX identifier or, failing that, by name.
+type CycloneDXLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// CycloneDXExternalReference links a component to a URL such as its website.
+type CycloneDXExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// CycloneDXProperty is a name-value pair with brewls-specific detail.
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CycloneDXDependency lists the components the component ref depends on directly.
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// Render implements Renderer.
+func (r CycloneDXRenderer) Render(view *View, writer io.Writer) error {
+	serial, err := newUUID()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.Document(view, "urn:uuid:"+serial))
+}
+
+// Document builds the BOM for view with the given serial number.
+func (r CycloneDXRenderer) Document(view *View, serialNumber string) CycloneDXDocument {
+	timestamp := r.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	doc := CycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  CycloneDXSpecVersion,
+		SerialNumber: serialNumber,
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: timestamp.UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{Components: []CycloneDXComponent{
+				{Type: "application", Name: "brewls", Version: r.ToolVersion},
+			}},
+		},
+		Components:   []CycloneDXComponent{},
+		Dependencies: []CycloneDXDependency{},
+	}
+	if r.Hostname != "" {
+		doc.Metadata.Component = &CycloneDXComponent{Type: "device", Name: r.Hostname}
+	}
+
+	rows := view.Rows()
+	refs := make(map[string]string, len(rows)) // by name; formulae win over casks of the same name
+	for _, row := range rows {
+		ref := PackageURL(row.Type, row.Name, row.Version, row.Tap)
+		if _, ok := refs[row.Name]; !ok || row.Type == PackageTypeFormula {
+			refs[row.Name] = ref
+		}
+		doc.Components = append(doc.Components, cycloneDXComponent(row, ref))
+	}
+
+	installation := view.Installation
+	if installation == nil {
+		installation = &BrewInfo{}
+	}
+	deps := InstalledDependencies(installation)
+	for _, row := range rows {
+		dependency := CycloneDXDependency{Ref: PackageURL(row.Type, row.Name, row.Version, row.Tap), DependsOn: []string{}}
+		if row.Type == PackageTypeFormula {
+			for _, dep := range deps[row.Name] {
+				if ref, ok := refs[dep]; ok {
+					dependency.DependsOn = append(dependency.DependsOn, ref)
+				}
+			}
+		}
+		doc.Dependencies = append(doc.Dependencies, dependency)
+	}
+	return doc
+}
+
+func cycloneDXComponent(row Row, ref string) CycloneDXComponent {
+	component := CycloneDXComponent{
+		Type:        "library",
+		BOMRef:      ref,
+		Name:        row.Name,
+		Version:     row.Version,
+		Description: row.Desc,
+		PURL:        ref,
+	}
+	// Casks are applications; formulae only when someone asked for them, as
+	// the rest were pulled in for other packages.
+	if row.Type == PackageTypeCask || (row.Formula != nil && row.Formula.InstalledOnRequest()) {
+		component.Type = "application"
+	}
+	if row.Formula != nil && row.Formula.License != "" {
+		component.Licenses = []CycloneDXLicenseChoice{cycloneDXLicense(row.Formula.License)}
+	}
+	if row.Homepage != "" {
+		component.ExternalReferences = []CycloneDXExternalReference{{Type: "website", URL: row.Homepage}}
+	}
+	component.Properties = []CycloneDXProperty{{Name: "brewls:type", Value: row.Type}}
+	if row.Tap != "" {
+		component.Properties = append(component.Properties, CycloneDXProperty{Name: "brewls:tap", Value: row.Tap})
+	}
+	if row.IsRoot {
+		component.Properties = append(component.Properties, CycloneDXProperty{Name: "brewls:root", Value: "true"})
+	}
+	return component
+}
+
+// cycloneDXLicense turns a Homebrew license string into an SPDX identifier,
+// an SPDX expression, or a plain name. CycloneDX only takes identifiers from
+// the SPDX License List as an ID, so single licenses that are not on it, such
+// as "Public Domain" or "LicenseRef-foo", are given by name.
+func cycloneDXLicense(license string) CycloneDXLicenseChoice {
+	expression, ok := SPDXExpression(license)
+	if !ok || spdxIDPattern.MatchString(expression) {
+		if id, ok := SPDXLicenseID(strings.TrimSpace(license)); ok {
+			return CycloneDXLicenseChoice{License: &CycloneDXLicense{ID: id}}
+		}
+		return CycloneDXLicenseChoice{License: &CycloneDXLicense{Name: strings.TrimSpace(license)}}
+	}
+	return CycloneDXLicenseChoice{Expression: expression}
+}
+
+var (
+	spdxIDPattern       = regexp.MustCompile(`^[A-Za-z0-9.+-]+$`)
+	spdxOperatorPattern = regexp.MustCompile(`(?i)\s+(and|or|with)\s+`)
+)
+
+// SPDXExpression converts the license of a formula, as brew reports it, to an
+// SPDX license expression. brew writes operators in lower case, e.g.
+// "MIT or Apache-2.0", and uses names such as "Public Domain" for licenses
+// SPDX has no identifier for, which are reported as not ok.
+func SPDXExpression(license string) (string, bool) {
+	expression := spdxOperatorPattern.ReplaceAllStringFunc(strings.TrimSpace(license), func(op string) string {
+		return " " + strings.ToUpper(strings.TrimSpace(op)) + " "
+	})
+	// Identifiers and operators must alternate once parentheses are ignored.
+	terms := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+	for i, term := range terms {
+		operator := term == "AND" || term == "OR" || term == "WITH"
+		if operator != (i%2 == 1) || !operator && !spdxIDPattern.MatchString(term) {
+			return "", false
+		}
+	}
+	if len(terms)%2 == 0 {
+		return "", false
+	}
+	return expression, true
+}
+
+// PackageURL returns the package URL (purl) of an installed package, e.g.
+// pkg:brew/python%403.12@3.12.4 for a formula and
+// pkg:brew/firefox@125.0?type=cask for a cask. Packages from third-party
+// taps carry the tap, as in pkg:brew/terraform@1.5.7?tap=hashicorp%2Ftap.
+func PackageURL(typ, name, version, tap string) string {
+	purl := "pkg:brew/" + purlEscape(name)
+	if version != "" {
+		purl += "@" + purlEscape(version)
+	}
+	var qualifiers []string
+	if !slices.Contains(officialTaps, tap) {
+		qualifiers = append(qualifiers, "tap="+purlEscape(tap))
+	}
+	if typ == PackageTypeCask {
+		qualifiers = append(qualifiers, "type=cask")
+	}
+	if len(qualifiers) > 0 {
+		purl += "?" + strings.Join(qualifiers, "&")
+	}
+	return purl
+}
+
+// purlEscape percent-encodes everything but letters, digits and ".-_~".
+func purlEscape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(".-_~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
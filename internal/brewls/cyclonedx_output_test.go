@@ -0,0 +1,186 @@
+package brewls_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+// validateCycloneDX checks the structure the CycloneDX 1.5 JSON schema
+// requires of a BOM, and that every reference points at a component.
+func validateCycloneDX(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, data)
+	}
+	if doc["bomFormat"] != "CycloneDX" || doc["specVersion"] != "1.5" || doc["version"] != 1.0 {
+		t.Errorf("Unexpected header: %v %v %v", doc["bomFormat"], doc["specVersion"], doc["version"])
+	}
+	serial, _ := doc["serialNumber"].(string)
+	if !regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(serial) {
+		t.Errorf("Invalid serial number %q", serial)
+	}
+	metadata, _ := doc["metadata"].(map[string]any)
+	if _, err := time.Parse(time.RFC3339, metadata["timestamp"].(string)); err != nil {
+		t.Errorf("Invalid timestamp: %v", err)
+	}
+
+	types := []string{"application", "framework", "library", "container", "platform", "operating-system", "device", "device-driver", "firmware", "file", "machine-learning-model", "data"}
+	refs := make(map[string]bool)
+	for _, c := range doc["components"].([]any) {
+		component := c.(map[string]any)
+		ref, _ := component["bom-ref"].(string)
+		if ref == "" || refs[ref] {
+			t.Errorf("Missing or duplicate bom-ref in %v", component)
+		}
+		refs[ref] = true
+		if !slices.Contains(types, component["type"].(string)) || component["name"] == "" {
+			t.Errorf("Invalid component type or name in %v", component)
+		}
+		if purl, _ := component["purl"].(string); !regexp.MustCompile(`^pkg:brew/[^@?]+@[^?]+(\?.*)?$`).MatchString(purl) {
+			t.Errorf("Invalid purl %q", purl)
+		}
+		licenses, _ := component["licenses"].([]any)
+		for _, l := range licenses {
+			choice := l.(map[string]any)
+			if _, hasLicense := choice["license"]; hasLicense == (choice["expression"] != nil) {
+				t.Errorf("A license choice needs exactly one of license and expression: %v", choice)
+			}
+			// license.id is an enum of the SPDX License List.
+			if license, _ := choice["license"].(map[string]any); license != nil {
+				if id, ok := license["id"].(string); ok {
+					if canonical, known := brewls.SPDXLicenseID(id); !known || canonical != id {
+						t.Errorf("license.id %q is not on the SPDX License List", id)
+					}
+				}
+			}
+		}
+	}
+	for _, d := range doc["dependencies"].([]any) {
+		dependency := d.(map[string]any)
+		if !refs[dependency["ref"].(string)] {
+			t.Errorf("Dependency on unknown ref %v", dependency["ref"])
+		}
+		for _, on := range dependency["dependsOn"].([]any) {
+			if !refs[on.(string)] {
+				t.Errorf("%v depends on unknown ref %v", dependency["ref"], on)
+			}
+		}
+	}
+	return doc
+}
+
+func TestCycloneDXRenderer(t *testing.T) {
+	info := sampleBrewInfo()
+	info.Formulae[0].License = "GPL-2.0-only"
+	info.Formulae[0].Homepage = "https://git-scm.com"
+	info.Formulae[1].License = "BSD-3-Clause or MIT"
+	view := brewls.NewView(info, brewls.ViewOptions{})
+	view.Installation = info
+
+	renderer := brewls.CycloneDXRenderer{Timestamp: time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC), ToolVersion: "v1.2.3", Hostname: "laptop"}
+	var buf bytes.Buffer
+	if err := renderer.Render(view, &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	validateCycloneDX(t, buf.Bytes())
+
+	var doc brewls.CycloneDXDocument
+	json.Unmarshal(buf.Bytes(), &doc)
+	if doc.Metadata.Timestamp != "2026-10-01T09:30:00Z" || doc.Metadata.Tools.Components[0].Version != "v1.2.3" || doc.Metadata.Component.Name != "laptop" {
+		t.Errorf("Unexpected metadata %+v", doc.Metadata)
+	}
+
+	git := doc.Components[0]
+	if git.Name != "git" || git.Type != "application" || git.PURL != "pkg:brew/git@2.44.0" ||
+		git.Licenses[0].License.ID != "GPL-2.0-only" || git.ExternalReferences[0].URL != "https://git-scm.com" {
+		t.Errorf("Unexpected git component %+v", git)
+	}
+	if pcre2 := doc.Components[1]; pcre2.Type != "library" || pcre2.Licenses[0].Expression != "BSD-3-Clause OR MIT" {
+		t.Errorf("Unexpected pcre2 component %+v", pcre2)
+	}
+	if firefox := doc.Components[2]; firefox.PURL != "pkg:brew/firefox@125.0?type=cask" || firefox.Licenses != nil {
+		t.Errorf("Unexpected firefox component %+v", firefox)
+	}
+	want := brewls.CycloneDXDependency{Ref: "pkg:brew/git@2.44.0", DependsOn: []string{"pkg:brew/pcre2@10.42"}}
+	if !slices.ContainsFunc(doc.Dependencies, func(d brewls.CycloneDXDependency) bool {
+		return d.Ref == want.Ref && slices.Equal(d.DependsOn, want.DependsOn)
+	}) {
+		t.Errorf("Expected %+v in %+v", want, doc.Dependencies)
+	}
+
+	// Dependencies outside the selection are left out rather than dangling.
+	selected := brewls.NewView(info, brewls.ViewOptions{})
+	selected.Installation = info
+	selected.Formulae = selected.Formulae[:1]
+	buf.Reset()
+	renderer.Render(selected, &buf)
+	validateCycloneDX(t, buf.Bytes())
+}
+
+func TestCycloneDXLicenses(t *testing.T) {
+	tests := []struct {
+		license  string
+		expected brewls.CycloneDXLicenseChoice
+	}{
+		{"MIT", brewls.CycloneDXLicenseChoice{License: &brewls.CycloneDXLicense{ID: "MIT"}}},
+		{"apache-2.0", brewls.CycloneDXLicenseChoice{License: &brewls.CycloneDXLicense{ID: "Apache-2.0"}}},
+		{"GPL-2.0+", brewls.CycloneDXLicenseChoice{License: &brewls.CycloneDXLicense{ID: "GPL-2.0+"}}},
+		{"LicenseRef-foo", brewls.CycloneDXLicenseChoice{License: &brewls.CycloneDXLicense{Name: "LicenseRef-foo"}}},
+		{"GPL-2.0-onyl", brewls.CycloneDXLicenseChoice{License: &brewls.CycloneDXLicense{Name: "GPL-2.0-onyl"}}},
+		{"Public Domain", brewls.CycloneDXLicenseChoice{License: &brewls.CycloneDXLicense{Name: "Public Domain"}}},
+		{"MIT or LicenseRef-foo", brewls.CycloneDXLicenseChoice{Expression: "MIT OR LicenseRef-foo"}},
+	}
+	for _, tt := range tests {
+		info := &brewls.BrewInfo{Formulae: []brewls.Formula{{Name: "x", License: tt.license, Installed: []brewls.Installed{{Version: "1"}}}}}
+		doc := brewls.CycloneDXRenderer{}.Document(brewls.NewView(info, brewls.ViewOptions{}), "urn:uuid:0")
+		if got := doc.Components[0].Licenses; len(got) != 1 || !reflect.DeepEqual(got[0], tt.expected) {
+			t.Errorf("License %q became %+v, expected %+v", tt.license, got, tt.expected)
+		}
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		typ, name, version, tap string
+		expected                string
+	}{
+		{"formula", "git", "2.44.0", "homebrew/core", "pkg:brew/git@2.44.0"},
+		{"formula", "python@3.12", "3.12.4_1", "homebrew/core", "pkg:brew/python%403.12@3.12.4_1"},
+		{"formula", "terraform", "1.5.7", "hashicorp/tap", "pkg:brew/terraform@1.5.7?tap=hashicorp%2Ftap"},
+		{"cask", "docker", "4.28.0,139208", "homebrew/cask", "pkg:brew/docker@4.28.0%2C139208?type=cask"},
+	}
+	for _, tt := range tests {
+		if got := brewls.PackageURL(tt.typ, tt.name, tt.version, tt.tap); got != tt.expected {
+			t.Errorf("PackageURL(%q, %q, %q, %q) = %q, expected %q", tt.typ, tt.name, tt.version, tt.tap, got, tt.expected)
+		}
+	}
+}
+
+func TestSPDXExpression(t *testing.T) {
+	tests := []struct {
+		license, expected string
+		ok                bool
+	}{
+		{"MIT", "MIT", true},
+		{"MIT or Apache-2.0", "MIT OR Apache-2.0", true},
+		{"GPL-2.0-or-later with Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", true},
+		{"(MIT or Apache-2.0) and BSD-3-Clause", "(MIT OR Apache-2.0) AND BSD-3-Clause", true},
+		{"Public Domain", "", false},
+		{"Cannot Represent", "", false},
+		{"MIT or", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := brewls.SPDXExpression(tt.license); got != tt.expected || ok != tt.ok {
+			t.Errorf("SPDXExpression(%q) = %q, %v; expected %q, %v", tt.license, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
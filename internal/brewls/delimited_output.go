@@ -0,0 +1,30 @@
+package brewls
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// DefaultListSeparator joins multi-value cells such as Installed By.
+const DefaultListSeparator = ", "
+
+// writeDelimited writes a header row and one row per package. The columns and
+// row order match TableRenderer, but names carry no root marker, since the root
+// column holds that; encoding/csv quotes cells containing the delimiter, quotes
+// or newlines.
+func writeDelimited(view *View, writer io.Writer, comma rune, listSeparator string) error {
+	w := csv.NewWriter(writer)
+	w.Comma = comma
+
+	if err := w.Write(view.Header()); err != nil {
+		return err
+	}
+	for _, row := range view.Rows() {
+		if err := w.Write(view.Cells(row, listSeparator)); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,106 @@
+package brewls_test
+
+import (
+	"bytes"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func delimitedTestInfo() *brewls.BrewInfo {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "zlib", Installed: []brewls.Installed{{Version: "1.3.1"}}},
+			{Name: "curl", Installed: []brewls.Installed{{Version: "8.7.1", InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "zlib"}}}}},
+			{Name: "libxml2", Installed: []brewls.Installed{{Version: "2.12.6", RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "zlib"}}}}},
+		},
+		Casks: []brewls.Cask{
+			{Token: "iterm2", Name: []string{"iTerm2"}, Installed: "3.4.23"},
+		},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func TestDelimitedRendererCSV(t *testing.T) {
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv("BREWLS_FEATURES", "")
+
+	var buf bytes.Buffer
+	if err := (brewls.DelimitedRenderer{Comma: ',', ListSeparator: brewls.DefaultListSeparator}).Render(brewls.NewView(delimitedTestInfo(), brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	expected := `Name,Version,Installed By
+zlib,1.3.1,"curl, libxml2"
+curl,8.7.1,
+libxml2,2.12.6,
+iTerm2,3.4.23,
+`
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestDelimitedRendererCSVHonorsColumnAndSortFlags(t *testing.T) {
+	t.Setenv(brewls.FeatureFlagsEnv, "installed-by-count")
+	t.Setenv("BREWLS_FEATURES", "sort-output")
+
+	var buf bytes.Buffer
+	if err := (brewls.DelimitedRenderer{Comma: ',', ListSeparator: "|"}).Render(brewls.NewView(delimitedTestInfo(), brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	expected := `Name,Version,Installed By,Installed By Count
+curl,8.7.1,,0
+libxml2,2.12.6,,0
+zlib,1.3.1,curl|libxml2,2
+iTerm2,3.4.23,,0
+`
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestDelimitedRendererTSV(t *testing.T) {
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv("BREWLS_FEATURES", "")
+
+	info := delimitedTestInfo()
+	info.Casks[0].Name = []string{"Tab\tName"}
+
+	var buf bytes.Buffer
+	if err := (brewls.DelimitedRenderer{Comma: '\t', ListSeparator: brewls.DefaultListSeparator}).Render(brewls.NewView(info, brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	expected := "Name\tVersion\tInstalled By\n" +
+		"zlib\t1.3.1\tcurl, libxml2\n" +
+		"curl\t8.7.1\t\n" +
+		"libxml2\t2.12.6\t\n" +
+		"\"Tab\tName\"\t3.4.23\t\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+func TestDelimitedRendererSelectedColumns(t *testing.T) {
+	columns, err := brewls.ResolveColumns([]string{"type", "name", "root"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := (brewls.DelimitedRenderer{Comma: ','}).Render(brewls.NewView(delimitedTestInfo(), brewls.ViewOptions{Columns: columns}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	expected := `Type,Name,Root
+formula,zlib,
+formula,curl,yes
+formula,libxml2,
+cask,iTerm2,yes
+`
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
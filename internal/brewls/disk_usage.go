@@ -0,0 +1,107 @@
+package brewls
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BrewPrefix returns the Homebrew installation prefix reported by `brew --prefix`.
+func BrewPrefix() (string, error) {
+	if _, err := LookPath(BrewPath); err != nil {
+		return "", fmt.Errorf("Homebrew 'brew' command not found in PATH: %w", err)
+	}
+
+	cmd := ExecCommand(BrewPath, "--prefix")
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("brew --prefix finished with error: %w; Stderr: %s", err, stderrBuf.String())
+	}
+	return strings.TrimSpace(stdoutBuf.String()), nil
+}
+
+// BrewVersion returns the first line of `brew --version`, e.g. "Homebrew 4.3.1".
+func BrewVersion() (string, error) {
+	if _, err := LookPath(BrewPath); err != nil {
+		return "", fmt.Errorf("Homebrew 'brew' command not found in PATH: %w", err)
+	}
+
+	cmd := ExecCommand(BrewPath, "--version")
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("brew --version finished with error: %w; Stderr: %s", err, stderrBuf.String())
+	}
+	line, _, _ := strings.Cut(stdoutBuf.String(), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// PopulateDiskUsage sets SizeBytes for every formula and cask by summing the regular
+// files in its Cellar keg or Caskroom directory. With several prefixes, the first
+// one containing the package's directory is used. Packages whose directory cannot
+// be found or read are left at zero.
+func PopulateDiskUsage(info *BrewInfo, prefixes ...string) {
+	for i := range info.Formulae {
+		f := &info.Formulae[i]
+		if version := f.InstalledVersion(); version != "" {
+			f.SizeBytes = directorySize(findInPrefixes(prefixes, "Cellar", f.Name, version))
+		}
+	}
+	for i := range info.Casks {
+		c := &info.Casks[i]
+		if c.Installed != "" {
+			c.SizeBytes = directorySize(findInPrefixes(prefixes, "Caskroom", c.Token, c.Installed))
+		}
+	}
+}
+
+// findInPrefixes joins elem to the first prefix where the result exists,
+// falling back to the first prefix.
+func findInPrefixes(prefixes []string, elem ...string) string {
+	if len(prefixes) == 0 {
+		return ""
+	}
+	for _, prefix := range prefixes {
+		path := filepath.Join(append([]string{prefix}, elem...)...)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(append([]string{prefixes[0]}, elem...)...)
+}
+
+func directorySize(root string) int64 {
+	var total int64
+	_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries rather than failing the whole listing
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// HumanizeBytes formats a byte count using binary units, e.g. 1536 -> "1.5 KiB".
+func HumanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
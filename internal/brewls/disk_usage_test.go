@@ -0,0 +1,87 @@
+package brewls_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestPopulateDiskUsage(t *testing.T) {
+	prefix := t.TempDir()
+	writeFile := func(path string, size int) {
+		t.Helper()
+		full := filepath.Join(prefix, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("Cellar/git/2.44.0/bin/git", 1000)
+	writeFile("Cellar/git/2.44.0/share/doc", 24)
+	writeFile("Cellar/git/2.43.0/bin/git", 5000) // older keg is not counted
+	writeFile("Caskroom/firefox/125.0/Firefox.app", 300)
+
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "git", Installed: []brewls.Installed{{Version: "2.44.0"}}},
+			{Name: "missing", Installed: []brewls.Installed{{Version: "1.0"}}},
+		},
+		Casks: []brewls.Cask{{Token: "firefox", Installed: "125.0"}},
+	}
+	brewls.PopulateDiskUsage(info, prefix)
+
+	if got := info.Formulae[0].SizeBytes; got != 1024 {
+		t.Errorf("Expected git size 1024, got %d", got)
+	}
+	if got := info.Formulae[1].SizeBytes; got != 0 {
+		t.Errorf("Expected missing keg size 0, got %d", got)
+	}
+	if got := info.Casks[0].SizeBytes; got != 300 {
+		t.Errorf("Expected firefox size 300, got %d", got)
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		1536:              "1.5 KiB",
+		5 * 1024 * 1024:   "5.0 MiB",
+		3 << 30:           "3.0 GiB",
+		int64(1.5 * 1e12): "1.4 TiB",
+	}
+	for n, want := range tests {
+		if got := brewls.HumanizeBytes(n); got != want {
+			t.Errorf("HumanizeBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPopulateDiskUsageSearchesPrefixes(t *testing.T) {
+	intel, arm := t.TempDir(), t.TempDir()
+	for path, size := range map[string]int{
+		filepath.Join(intel, "Cellar/wget/1.24.5/bin/wget"): 10,
+		filepath.Join(arm, "Cellar/git/2.44.0/bin/git"):     20,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info := &brewls.BrewInfo{Formulae: []brewls.Formula{
+		{Name: "git", Installed: []brewls.Installed{{Version: "2.44.0"}}},
+		{Name: "wget", Installed: []brewls.Installed{{Version: "1.24.5"}}},
+	}}
+	brewls.PopulateDiskUsage(info, arm, intel)
+
+	if info.Formulae[0].SizeBytes != 20 || info.Formulae[1].SizeBytes != 10 {
+		t.Errorf("Expected sizes 20 and 10, got %d and %d", info.Formulae[0].SizeBytes, info.Formulae[1].SizeBytes)
+	}
+}
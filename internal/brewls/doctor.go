@@ -0,0 +1,90 @@
+package brewls
+
+import (
+	"fmt"
+	"sort"
+
+	"brewls/pkg/version"
+)
+
+// Severity ranks a Diagnostic reported by Diagnose.
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is one finding about the installed packages.
+type Diagnostic struct {
+	Severity Severity
+	Package  string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Package, d.Message)
+}
+
+// Diagnose inspects an installation whose reverse dependency graph has been built
+// and reports missing runtime dependencies, runtime dependencies recorded at a
+// different version than the one installed, deprecated or disabled packages, and
+// formulae that were only installed as dependencies but are no longer needed.
+// Results are sorted by package name.
+func Diagnose(info *BrewInfo) []Diagnostic {
+	installed := make(map[string]string, len(info.Formulae))
+	for _, f := range info.Formulae {
+		installed[f.Name] = f.InstalledVersion()
+		if f.FullName != "" {
+			installed[f.FullName] = f.InstalledVersion()
+		}
+	}
+
+	var diagnostics []Diagnostic
+	add := func(severity Severity, pkg, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{Severity: severity, Package: pkg, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, f := range info.Formulae {
+		if len(f.Installed) == 0 {
+			add(SeverityWarning, f.Name, "no installed version recorded")
+			continue
+		}
+		for _, rd := range f.Installed[len(f.Installed)-1].RuntimeDependencies {
+			installedVersion, ok := installed[rd.FullName]
+			if !ok {
+				add(SeverityWarning, f.Name, "runtime dependency %s is not installed", rd.FullName)
+				continue
+			}
+			if rd.Version != "" && installedVersion != "" && !sameRelease(rd.Version, installedVersion) {
+				add(SeverityWarning, f.Name, "built against %s %s but %s is installed", rd.FullName, rd.Version, installedVersion)
+			}
+		}
+		if f.Disabled {
+			add(SeverityWarning, f.Name, "formula is disabled")
+		} else if f.Deprecated {
+			add(SeverityWarning, f.Name, "formula is deprecated")
+		}
+		if f.IsOrphan() {
+			add(SeverityInfo, f.Name, "installed as a dependency but no longer needed (see brew autoremove)")
+		}
+	}
+	for _, c := range info.Casks {
+		if c.Disabled {
+			add(SeverityWarning, c.Token, "cask is disabled")
+		} else if c.Deprecated {
+			add(SeverityWarning, c.Token, "cask is deprecated")
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Package < diagnostics[j].Package
+	})
+	return diagnostics
+}
+
+// sameRelease reports whether two versions name the same release, ignoring
+// their "_N" revisions, so "3.3.0_1" and "3.3.0" match.
+func sameRelease(a, b string) bool {
+	return version.Parse(a).WithoutRevision().Compare(version.Parse(b).WithoutRevision()) == 0
+}
@@ -0,0 +1,47 @@
+package brewls_test
+
+import (
+	"reflect"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestDiagnose(t *testing.T) {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "curl", Installed: []brewls.Installed{{Version: "8.7.1", InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{
+				{FullName: "openssl@3", Version: "3.2.1"},
+				{FullName: "zstd", Version: "1.5.6"},
+				{FullName: "libssh2", Version: "1.11.0"},
+			}}}},
+			{Name: "openssl@3", Installed: []brewls.Installed{{Version: "3.3.0"}}},
+			{Name: "zstd", Installed: []brewls.Installed{{Version: "1.5.6_1"}}},
+			{Name: "youtube-dl", Deprecated: true, Installed: []brewls.Installed{{Version: "2021.12.17", InstalledOnRequest: true}}},
+			{Name: "leftover", Installed: []brewls.Installed{{Version: "1.0"}}},
+		},
+		Casks: []brewls.Cask{{Token: "old-app", Disabled: true}},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+
+	var got []string
+	for _, d := range brewls.Diagnose(info) {
+		got = append(got, d.String())
+	}
+	expected := []string{
+		"warning: curl: built against openssl@3 3.2.1 but 3.3.0 is installed",
+		"warning: curl: runtime dependency libssh2 is not installed",
+		"info: leftover: installed as a dependency but no longer needed (see brew autoremove)",
+		"warning: old-app: cask is disabled",
+		"warning: youtube-dl: formula is deprecated",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected diagnostics:\n%v\nGot:\n%v", expected, got)
+	}
+}
+
+func TestDiagnoseHealthyInstall(t *testing.T) {
+	if got := brewls.Diagnose(sampleBrewInfo()); len(got) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", got)
+	}
+}
@@ -1,24 +1,235 @@
 package brewls
 
 import (
+	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // FeatureFlagsEnv is the env var used to enable feature flags.
 const FeatureFlagsEnv = "BREWLS_FEATURE_FLAGS"
 
-const featureSortOutput = "sort-output"
+// LegacyFeatureFlagsEnv is the older env var for feature flags. It is still
+// read, but FeatureFlagsEnv wins when both mention the same flag.
+const LegacyFeatureFlagsEnv = "BREWLS_FEATURES"
 
-// IsFeatureEnabled reports whether a feature flag is enabled via FeatureFlagsEnv.
+const (
+	featureInstalledByCount = "installed-by-count"
+	featureSortOutput       = "sort-output"
+
+	// FeatureRecordHistory appends the changes brewls observes to the history
+	// store whenever it runs brew; see RecordHistory. The cli only follows the
+	// default on a terminal, so scripts have to turn it on explicitly.
+	FeatureRecordHistory = "record-history"
+)
+
+// FeatureStage describes how mature a feature flag is.
+type FeatureStage string
+
+const (
+	StageExperimental FeatureStage = "experimental" // May change or disappear without notice
+	StageBeta         FeatureStage = "beta"         // Expected to become the default behavior
+	StageStable       FeatureStage = "stable"       // Kept for compatibility; safe to rely on
+	StageDeprecated   FeatureStage = "deprecated"   // Superseded; will be removed
+)
+
+// FeatureFlag declares a feature flag and its default state.
+type FeatureFlag struct {
+	Name        string
+	Description string
+	Stage       FeatureStage
+	Default     bool
+}
+
+// Sources a feature flag's state can come from, lowest precedence first.
+const (
+	FeatureSourceDefault = "default"
+	FeatureSourceConfig  = "config"
+	FeatureSourceEnv     = "env"
+	FeatureSourceView    = "view"
+	FeatureSourceFlag    = "flag"
+)
+
+// FeatureState is the resolved state of a registered feature flag.
+type FeatureState struct {
+	FeatureFlag
+	Enabled bool
+	Source  string // One of the FeatureSource constants
+}
+
+// UnknownFeature is a feature flag name that was set somewhere but is not registered.
+type UnknownFeature struct {
+	Name   string
+	Source string // Where it was set, e.g. FeatureFlagsEnv or "--feature"
+}
+
+var (
+	featureMu       sync.RWMutex
+	featureRegistry = make(map[string]FeatureFlag)
+	featureOrder    []string
+	configFeatures  = make(map[string]bool)
+	viewFeatures    = make(map[string]bool)
+	cliFeatures     = make(map[string]bool)
+)
+
+func init() {
+	RegisterFeature(FeatureFlag{
+		Name:        featureInstalledByCount,
+		Description: "Add the installed_by_count column to the default columns (prefer --columns)",
+		Stage:       StageDeprecated,
+	})
+	RegisterFeature(FeatureFlag{
+		Name:        featureSortOutput,
+		Description: "Sort packages by name by default (prefer --sort=name)",
+		Stage:       StageDeprecated,
+	})
+	RegisterFeature(FeatureFlag{
+		Name:        FeatureRecordHistory,
+		Description: "Record installs, upgrades and removals for brewls history whenever brew runs",
+		Stage:       StageBeta,
+		Default:     true,
+	})
+}
+
+// RegisterFeature adds a feature flag to the registry. Names are normalized to
+// lower case. It panics on an empty or duplicate name.
+func RegisterFeature(flag FeatureFlag) {
+	flag.Name = normalizeFeatureFlag(flag.Name)
+	if flag.Name == "" {
+		panic("brewls: RegisterFeature with empty name")
+	}
+
+	featureMu.Lock()
+	defer featureMu.Unlock()
+	if _, ok := featureRegistry[flag.Name]; ok {
+		panic(fmt.Sprintf("brewls: feature flag %q registered twice", flag.Name))
+	}
+	featureRegistry[flag.Name] = flag
+	featureOrder = append(featureOrder, flag.Name)
+}
+
+// SetConfigFeatures sets the feature flags enabled or disabled by the config file.
+func SetConfigFeatures(values map[string]bool) {
+	featureMu.Lock()
+	defer featureMu.Unlock()
+	configFeatures = normalizeFeatureMap(values)
+}
+
+// SetViewFeatures sets the feature flags enabled or disabled by the view
+// selected with --view.
+func SetViewFeatures(values map[string]bool) {
+	featureMu.Lock()
+	defer featureMu.Unlock()
+	viewFeatures = normalizeFeatureMap(values)
+}
+
+// SetCLIFeatures sets the feature flags enabled or disabled with --feature.
+func SetCLIFeatures(values map[string]bool) {
+	featureMu.Lock()
+	defer featureMu.Unlock()
+	cliFeatures = normalizeFeatureMap(values)
+}
+
+// ParseFeatureSettings parses a comma-separated list of feature flags into
+// enabled states. A leading "-" disables a flag, e.g. "sort-output,-installed-by-count".
+func ParseFeatureSettings(raw string) map[string]bool {
+	result := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		name := normalizeFeatureFlag(item)
+		enabled := true
+		if rest, ok := strings.CutPrefix(name, "-"); ok {
+			name, enabled = strings.TrimSpace(rest), false
+		}
+		if name == "" {
+			continue
+		}
+		result[name] = enabled
+	}
+	return result
+}
+
+// resolveFeature returns whether name is enabled and which source decided it.
+// Precedence is --feature, then the view selected with --view, then the env
+// vars, then the config file, then the registered default. Callers must hold
+// featureMu.
+func resolveFeature(name string) (bool, string) {
+	if enabled, ok := cliFeatures[name]; ok {
+		return enabled, FeatureSourceFlag
+	}
+	if enabled, ok := viewFeatures[name]; ok {
+		return enabled, FeatureSourceView
+	}
+	if enabled, ok := ParseFeatureSettings(os.Getenv(FeatureFlagsEnv))[name]; ok {
+		return enabled, FeatureSourceEnv
+	}
+	if enabled, ok := ParseFeatureSettings(os.Getenv(LegacyFeatureFlagsEnv))[name]; ok {
+		return enabled, FeatureSourceEnv
+	}
+	if enabled, ok := configFeatures[name]; ok {
+		return enabled, FeatureSourceConfig
+	}
+	return featureRegistry[name].Default, FeatureSourceDefault
+}
+
+// IsFeatureEnabled reports whether a feature flag is enabled. Flags that are not
+// registered can still be enabled, so experiments need no registration.
 func IsFeatureEnabled(flag string) bool {
 	flag = normalizeFeatureFlag(flag)
 	if flag == "" {
 		return false
 	}
-	flags := ParseFeatureFlags(os.Getenv(FeatureFlagsEnv))
-	_, ok := flags[flag]
-	return ok
+
+	featureMu.RLock()
+	defer featureMu.RUnlock()
+	enabled, _ := resolveFeature(flag)
+	return enabled
+}
+
+// FeatureEnabled reports whether a feature flag is enabled.
+//
+// Deprecated: use IsFeatureEnabled. Both read the same registry and env vars.
+func FeatureEnabled(name string) bool {
+	return IsFeatureEnabled(name)
+}
+
+// FeatureStates returns every registered feature flag with its current state,
+// in registration order.
+func FeatureStates() []FeatureState {
+	featureMu.RLock()
+	defer featureMu.RUnlock()
+
+	states := make([]FeatureState, 0, len(featureOrder))
+	for _, name := range featureOrder {
+		enabled, source := resolveFeature(name)
+		states = append(states, FeatureState{FeatureFlag: featureRegistry[name], Enabled: enabled, Source: source})
+	}
+	return states
+}
+
+// UnknownFeatures returns the feature flag names set through --feature, the env
+// vars or the config file that are not registered, sorted by name.
+func UnknownFeatures() []UnknownFeature {
+	featureMu.RLock()
+	defer featureMu.RUnlock()
+
+	var unknown []UnknownFeature
+	collect := func(values map[string]bool, source string) {
+		for name := range values {
+			if _, ok := featureRegistry[name]; !ok {
+				unknown = append(unknown, UnknownFeature{Name: name, Source: source})
+			}
+		}
+	}
+	collect(cliFeatures, "--feature")
+	collect(viewFeatures, "view")
+	collect(ParseFeatureSettings(os.Getenv(FeatureFlagsEnv)), FeatureFlagsEnv)
+	collect(ParseFeatureSettings(os.Getenv(LegacyFeatureFlagsEnv)), LegacyFeatureFlagsEnv)
+	collect(configFeatures, "config")
+
+	sort.SliceStable(unknown, func(i, j int) bool { return unknown[i].Name < unknown[j].Name })
+	return unknown
 }
 
 // ParseFeatureFlags parses a comma-separated list of feature flags.
@@ -34,25 +245,14 @@ func ParseFeatureFlags(raw string) map[string]struct{} {
 	return result
 }
 
-// FeatureEnabled reports whether a feature flag is enabled via BREWLS_FEATURES.
-func FeatureEnabled(name string) bool {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return false
-	}
-
-	raw := os.Getenv("BREWLS_FEATURES")
-	if raw == "" {
-		return false
-	}
-
-	for _, feature := range strings.Split(raw, ",") {
-		if strings.TrimSpace(feature) == name {
-			return true
+func normalizeFeatureMap(values map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(values))
+	for name, enabled := range values {
+		if name = normalizeFeatureFlag(name); name != "" {
+			result[name] = enabled
 		}
 	}
-
-	return false
+	return result
 }
 
 func normalizeFeatureFlag(flag string) string {
@@ -64,3 +64,84 @@ func TestIsFeatureEnabled(t *testing.T) {
 		t.Fatalf("expected gamma to be disabled")
 	}
 }
+
+func TestParseFeatureSettings(t *testing.T) {
+	got := ParseFeatureSettings(" Sort-Output, -installed-by-count ,,- ")
+	expected := map[string]bool{"sort-output": true, "installed-by-count": false}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("ParseFeatureSettings = %v, want %v", got, expected)
+	}
+}
+
+func TestFeaturePrecedence(t *testing.T) {
+	t.Setenv(FeatureFlagsEnv, "")
+	t.Setenv(LegacyFeatureFlagsEnv, "")
+	t.Cleanup(func() {
+		SetConfigFeatures(nil)
+		SetViewFeatures(nil)
+		SetCLIFeatures(nil)
+	})
+
+	state := func(name string) FeatureState {
+		for _, s := range FeatureStates() {
+			if s.Name == name {
+				return s
+			}
+		}
+		t.Fatalf("feature %q is not registered", name)
+		return FeatureState{}
+	}
+
+	if s := state(featureSortOutput); s.Enabled || s.Source != FeatureSourceDefault {
+		t.Fatalf("expected default off, got %+v", s)
+	}
+
+	SetConfigFeatures(map[string]bool{"Sort-Output": true})
+	if s := state(featureSortOutput); !s.Enabled || s.Source != FeatureSourceConfig {
+		t.Fatalf("expected config to enable, got %+v", s)
+	}
+
+	t.Setenv(LegacyFeatureFlagsEnv, "-sort-output")
+	if s := state(featureSortOutput); s.Enabled || s.Source != FeatureSourceEnv {
+		t.Fatalf("expected legacy env to override config, got %+v", s)
+	}
+
+	t.Setenv(FeatureFlagsEnv, "sort-output")
+	if !IsFeatureEnabled(featureSortOutput) || !FeatureEnabled(featureSortOutput) {
+		t.Fatalf("expected %s to override %s", FeatureFlagsEnv, LegacyFeatureFlagsEnv)
+	}
+
+	SetViewFeatures(map[string]bool{featureSortOutput: false})
+	if s := state(featureSortOutput); s.Enabled || s.Source != FeatureSourceView {
+		t.Fatalf("expected the view to override env, got %+v", s)
+	}
+
+	SetCLIFeatures(map[string]bool{featureSortOutput: true})
+	if s := state(featureSortOutput); !s.Enabled || s.Source != FeatureSourceFlag {
+		t.Fatalf("expected --feature to override the view, got %+v", s)
+	}
+}
+
+func TestUnknownFeatures(t *testing.T) {
+	t.Setenv(FeatureFlagsEnv, "sort-output,typo")
+	t.Setenv(LegacyFeatureFlagsEnv, "")
+	SetCLIFeatures(map[string]bool{"another": true, featureInstalledByCount: true})
+	t.Cleanup(func() { SetCLIFeatures(nil) })
+
+	expected := []UnknownFeature{
+		{Name: "another", Source: "--feature"},
+		{Name: "typo", Source: FeatureFlagsEnv},
+	}
+	if got := UnknownFeatures(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("UnknownFeatures = %v, want %v", got, expected)
+	}
+}
+
+func TestRegisterFeaturePanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected a panic for a duplicate feature flag")
+		}
+	}()
+	RegisterFeature(FeatureFlag{Name: "SORT-OUTPUT"})
+}
@@ -0,0 +1,485 @@
+package brewls
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Filter is a compiled --filter expression. Expressions combine comparisons over
+// registered columns with &&, || and !, for example:
+//
+//	root && !outdated
+//	installed_by_count > 3
+//	tap == "homebrew/core"
+//	name =~ "^python"
+//
+// A bare column name must be a bool column. Strings compare with == != < <= > >=
+// and match regular expressions with =~ and !~; numbers and times use the ordering
+// operators; list columns such as installed_by match when any element matches.
+type Filter struct {
+	source  string
+	root    filterNode
+	columns map[string]bool // keys of the columns the expression refers to
+}
+
+// FilterError reports a problem with a filter expression and where it occurred.
+type FilterError struct {
+	Expr string // the full expression
+	Pos  int    // byte offset of the offending token
+	Len  int    // length of the offending token, at least 1
+	Msg  string
+}
+
+func (e *FilterError) Error() string {
+	// Pos and Len count bytes; the column and caret count characters.
+	pos := min(max(e.Pos, 0), len(e.Expr))
+	column := utf8.RuneCountInString(e.Expr[:pos])
+	width := max(utf8.RuneCountInString(e.Expr[pos:min(pos+e.Len, len(e.Expr))]), 1)
+	return fmt.Sprintf("invalid filter: %s at column %d\n  %s\n  %s%s",
+		e.Msg, column+1, e.Expr, strings.Repeat(" ", column), strings.Repeat("^", width))
+}
+
+// ParseFilter parses and type-checks a filter expression against the column registry.
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{expr: expr, tokens: tokens, columns: make(map[string]bool)}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "unexpected %s", tok.describe())
+	}
+	return &Filter{source: expr, root: node, columns: p.columns}, nil
+}
+
+// Match reports whether row satisfies the filter. A nil Filter matches every row.
+func (f *Filter) Match(row Row) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.eval(row)
+}
+
+// UsesColumn reports whether the expression refers to the column with the given key,
+// so callers can compute expensive values such as size only when needed.
+func (f *Filter) UsesColumn(key string) bool {
+	return f != nil && f.columns[key]
+}
+
+// And returns a filter matching rows that match both f and other. Either may
+// be nil, which matches every row.
+func (f *Filter) And(other *Filter) *Filter {
+	switch {
+	case f == nil:
+		return other
+	case other == nil:
+		return f
+	}
+	columns := make(map[string]bool, len(f.columns)+len(other.columns))
+	maps.Copy(columns, f.columns)
+	maps.Copy(columns, other.columns)
+	return &Filter{
+		source:  "(" + f.source + ") && (" + other.source + ")",
+		root:    andNode{f.root, other.root},
+		columns: columns,
+	}
+}
+
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.source
+}
+
+// --- Lexer ---
+
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string // operator or identifier text; unquoted value for strings
+	pos  int
+	len  int
+}
+
+func (t filterToken) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+var filterOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!"}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	i := 0
+	for i < len(expr) {
+		c, size := utf8.DecodeRuneInString(expr[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: tokLParen, text: "(", pos: i, len: 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: tokRParen, text: ")", pos: i, len: 1})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(expr) && rune(expr[end]) != c {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, &FilterError{Expr: expr, Pos: i, Len: len(expr) - i, Msg: "unterminated string"}
+			}
+			raw := expr[i : end+1]
+			value := raw[1 : len(raw)-1]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(raw)
+				if err != nil {
+					return nil, &FilterError{Expr: expr, Pos: i, Len: len(raw), Msg: "invalid string escape"}
+				}
+				value = unquoted
+			}
+			tokens = append(tokens, filterToken{kind: tokString, text: value, pos: i, len: len(raw)})
+			i = end + 1
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(expr) && expr[i+1] >= '0' && expr[i+1] <= '9':
+			end := i + 1
+			for end < len(expr) && (expr[end] >= '0' && expr[end] <= '9' || expr[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: tokNumber, text: expr[i:end], pos: i, len: end - i})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i + size
+			for end < len(expr) {
+				r, n := utf8.DecodeRuneInString(expr[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += n
+			}
+			tokens = append(tokens, filterToken{kind: tokIdent, text: expr[i:end], pos: i, len: end - i})
+			i = end
+		default:
+			matched := false
+			for _, op := range filterOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					tokens = append(tokens, filterToken{kind: tokOp, text: op, pos: i, len: len(op)})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &FilterError{Expr: expr, Pos: i, Len: size, Msg: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	return append(tokens, filterToken{kind: tokEOF, pos: len(expr)}), nil
+}
+
+// --- Parser ---
+
+type filterParser struct {
+	expr    string
+	tokens  []filterToken
+	next    int
+	columns map[string]bool
+}
+
+func (p *filterParser) peek() filterToken { return p.tokens[p.next] }
+
+func (p *filterParser) advance() filterToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *filterParser) errorAt(tok filterToken, format string, args ...any) error {
+	return &FilterError{Expr: p.expr, Pos: tok.pos, Len: tok.len, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *filterParser) isOp(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokOp && tok.text == text
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.isOp("!") {
+		p.advance()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected \")\" but found %s", closing.describe())
+		}
+		return node, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return constNode(true), nil
+		case "false":
+			return constNode(false), nil
+		}
+		column, ok := LookupColumn(tok.text)
+		if !ok {
+			return nil, p.errorAt(tok, "unknown field %q", tok.text)
+		}
+		p.columns[column.Key] = true
+		if op := p.peek(); op.kind == tokOp && isComparison(op.text) {
+			p.advance()
+			return p.parseComparison(column, op)
+		}
+		if column.Kind != KindBool {
+			return nil, p.errorAt(tok, "field %q is a %s, not a bool; compare it with an operator", column.Key, column.Kind)
+		}
+		return boolFieldNode{column}, nil
+	case tokEOF:
+		return nil, p.errorAt(tok, "expected a field or \"(\" but reached the end of the expression")
+	default:
+		return nil, p.errorAt(tok, "expected a field or \"(\" but found %s", tok.describe())
+	}
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~":
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseComparison(column Column, op filterToken) (filterNode, error) {
+	lit := p.advance()
+	if lit.kind != tokString && lit.kind != tokNumber && lit.kind != tokIdent {
+		return nil, p.errorAt(lit, "expected a value after %q but found %s", op.text, lit.describe())
+	}
+
+	node := compareNode{column: column, op: op.text}
+
+	if op.text == "=~" || op.text == "!~" {
+		if column.Kind != KindString && column.Kind != KindList {
+			return nil, p.errorAt(op, "operator %q needs a string or list field, but %q is a %s", op.text, column.Key, column.Kind)
+		}
+		if lit.kind != tokString {
+			return nil, p.errorAt(lit, "operator %q needs a quoted regular expression", op.text)
+		}
+		re, err := regexp.Compile(lit.text)
+		if err != nil {
+			return nil, p.errorAt(lit, "invalid regular expression: %v", err)
+		}
+		node.pattern = re
+		return node, nil
+	}
+
+	switch column.Kind {
+	case KindString, KindList:
+		if lit.kind != tokString {
+			return nil, p.errorAt(lit, "field %q is a %s; compare it with a quoted string", column.Key, column.Kind)
+		}
+		node.value = lit.text
+	case KindNumber:
+		if lit.kind != tokNumber {
+			return nil, p.errorAt(lit, "field %q is a number; compare it with a number", column.Key)
+		}
+		n, err := strconv.ParseInt(lit.text, 10, 64)
+		if err != nil {
+			return nil, p.errorAt(lit, "invalid integer %q", lit.text)
+		}
+		node.value = n
+	case KindBool:
+		if lit.kind != tokIdent || (lit.text != "true" && lit.text != "false") {
+			return nil, p.errorAt(lit, "field %q is a bool; compare it with true or false", column.Key)
+		}
+		if op.text != "==" && op.text != "!=" {
+			return nil, p.errorAt(op, "operator %q is not supported for bool field %q", op.text, column.Key)
+		}
+		node.value = lit.text == "true"
+	case KindTime:
+		if lit.kind != tokString {
+			return nil, p.errorAt(lit, "field %q is a time; compare it with a quoted date such as \"2024-01-31\"", column.Key)
+		}
+		t, err := parseFilterTime(lit.text)
+		if err != nil {
+			return nil, p.errorAt(lit, "invalid date %q (use YYYY-MM-DD or RFC 3339)", lit.text)
+		}
+		node.value = t
+	}
+
+	if column.Kind == KindList && op.text != "==" && op.text != "!=" {
+		return nil, p.errorAt(op, "operator %q is not supported for list field %q", op.text, column.Key)
+	}
+	return node, nil
+}
+
+func parseFilterTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+// --- Evaluation ---
+
+type filterNode interface {
+	eval(row Row) bool
+}
+
+type constNode bool
+
+func (n constNode) eval(Row) bool { return bool(n) }
+
+type andNode struct{ left, right filterNode }
+
+func (n andNode) eval(row Row) bool { return n.left.eval(row) && n.right.eval(row) }
+
+type orNode struct{ left, right filterNode }
+
+func (n orNode) eval(row Row) bool { return n.left.eval(row) || n.right.eval(row) }
+
+type notNode struct{ operand filterNode }
+
+func (n notNode) eval(row Row) bool { return !n.operand.eval(row) }
+
+type boolFieldNode struct{ column Column }
+
+func (n boolFieldNode) eval(row Row) bool {
+	v, _ := n.column.Value(row).(bool)
+	return v
+}
+
+type compareNode struct {
+	column  Column
+	op      string
+	value   any
+	pattern *regexp.Regexp
+}
+
+func (n compareNode) eval(row Row) bool {
+	actual := n.column.Value(row)
+	list, ok := actual.([]string)
+	if !ok {
+		return n.compareScalar(actual)
+	}
+
+	// For lists, == and =~ match when any element matches; != and !~ when none does.
+	positive, negate := n, false
+	switch n.op {
+	case "!=":
+		positive.op, negate = "==", true
+	case "!~":
+		positive.op, negate = "=~", true
+	}
+	for _, item := range list {
+		if positive.compareScalar(item) {
+			return !negate
+		}
+	}
+	return negate
+}
+
+func (n compareNode) compareScalar(actual any) bool {
+	switch n.op {
+	case "=~":
+		s, _ := actual.(string)
+		return n.pattern.MatchString(s)
+	case "!~":
+		s, _ := actual.(string)
+		return !n.pattern.MatchString(s)
+	}
+
+	cmp := compareValues(actual, n.value)
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
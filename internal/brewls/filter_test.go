@@ -0,0 +1,172 @@
+package brewls_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func filterTestRows() []brewls.Row {
+	return []brewls.Row{
+		{Type: "formula", Name: "python@3.12", Tap: "homebrew/core", IsRoot: true, InstalledBy: []string{}},
+		{Type: "formula", Name: "python@3.13", Tap: "homebrew/core", Outdated: true, InstalledBy: []string{"a", "b", "c", "d"}},
+		{Type: "formula", Name: "openssl@3", Tap: "homebrew/core", InstalledBy: []string{"curl", "python@3.13"}},
+		{Type: "formula", Name: "terraform", Tap: "hashicorp/tap", IsRoot: true, Outdated: true, InstalledAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{Type: "cask", Name: "firefox", IsRoot: true, InstalledAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local)},
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: "root", expected: []string{"python@3.12", "terraform", "firefox"}},
+		{expr: "root && !outdated", expected: []string{"python@3.12", "firefox"}},
+		{expr: "installed_by_count > 3", expected: []string{"python@3.13"}},
+		{expr: "count >= 2 || type == 'cask'", expected: []string{"python@3.13", "openssl@3", "firefox"}},
+		{expr: `tap == "homebrew/core"`, expected: []string{"python@3.12", "python@3.13", "openssl@3"}},
+		{expr: `tap != "homebrew/core" && type != "cask"`, expected: []string{"terraform"}},
+		{expr: `name =~ "^python"`, expected: []string{"python@3.12", "python@3.13"}},
+		{expr: `name !~ "@"`, expected: []string{"terraform", "firefox"}},
+		{expr: `!(root || outdated)`, expected: []string{"openssl@3"}},
+		{expr: `installed_by == "curl"`, expected: []string{"openssl@3"}},
+		{expr: `installed_by =~ "^python" && installed_by != "a"`, expected: []string{"openssl@3"}},
+		{expr: `installed_at >= "2024-01-01"`, expected: []string{"terraform"}},
+		{expr: `outdated == false && root == true`, expected: []string{"python@3.12", "firefox"}},
+		{expr: `true`, expected: []string{"python@3.12", "python@3.13", "openssl@3", "terraform", "firefox"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := brewls.ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter returned error: %v", err)
+			}
+			var matched []string
+			for _, row := range filterTestRows() {
+				if filter.Match(row) {
+					matched = append(matched, row.Name)
+				}
+			}
+			if !reflect.DeepEqual(matched, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		msg    string
+		pos    int
+		length int
+	}{
+		{expr: "rot && !outdated", msg: `unknown field "rot"`, pos: 0, length: 3},
+		{expr: "root && name", msg: `field "name" is a string, not a bool`, pos: 8, length: 4},
+		{expr: `installed_by_count > "3"`, msg: `compare it with a number`, pos: 21, length: 3},
+		{expr: `tap == 3`, msg: `compare it with a quoted string`, pos: 7, length: 1},
+		{expr: `name =~ "("`, msg: `invalid regular expression`, pos: 8, length: 3},
+		{expr: `count =~ "1"`, msg: `operator "=~" needs a string or list field`, pos: 6, length: 2},
+		{expr: `(root`, msg: `expected ")" but found end of expression`, pos: 5, length: 0},
+		{expr: `root outdated`, msg: `unexpected "outdated"`, pos: 5, length: 8},
+		{expr: `root &&`, msg: `reached the end of the expression`, pos: 7, length: 0},
+		{expr: `name == "open`, msg: `unterminated string`, pos: 8, length: 5},
+		{expr: `root & outdated`, msg: `unexpected character '&'`, pos: 5, length: 1},
+		{expr: `root → outdated`, msg: `unexpected character '→'`, pos: 5, length: 3},
+		{expr: `root && é`, msg: `unknown field "é"`, pos: 8, length: 2},
+		{expr: `installed_at < "yesterday"`, msg: `invalid date "yesterday"`, pos: 15, length: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := brewls.ParseFilter(tt.expr)
+			var filterErr *brewls.FilterError
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("Expected a FilterError, got %v", err)
+			}
+			if !strings.Contains(filterErr.Msg, tt.msg) {
+				t.Errorf("Expected message containing %q, got %q", tt.msg, filterErr.Msg)
+			}
+			if filterErr.Pos != tt.pos || filterErr.Len != tt.length {
+				t.Errorf("Expected token at %d (len %d), got %d (len %d)", tt.pos, tt.length, filterErr.Pos, filterErr.Len)
+			}
+		})
+	}
+}
+
+func TestFilterErrorPointsAtToken(t *testing.T) {
+	_, err := brewls.ParseFilter("root && rot")
+	expected := "invalid filter: unknown field \"rot\" at column 9\n  root && rot\n          ^^^"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error:\n%s\nGot:\n%v", expected, err)
+	}
+}
+
+func TestFilterErrorPointsAtNonASCIIToken(t *testing.T) {
+	_, err := brewls.ParseFilter(`name == "café" → root`)
+	expected := "invalid filter: unexpected character '→' at column 16\n  name == \"café\" → root\n                 ^"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error:\n%s\nGot:\n%v", expected, err)
+	}
+}
+
+func TestNewViewAppliesFilter(t *testing.T) {
+	filter, err := brewls.ParseFilter("root")
+	if err != nil {
+		t.Fatalf("ParseFilter returned error: %v", err)
+	}
+	if !filter.UsesColumn("root") || filter.UsesColumn("size") {
+		t.Errorf("Expected filter to use root only")
+	}
+
+	view := brewls.NewView(delimitedTestInfo(), brewls.ViewOptions{Filter: filter})
+	var names []string
+	for _, row := range view.Rows() {
+		names = append(names, row.Name)
+	}
+	if want := []string{"curl", "iterm2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+	if got := view.Formulae; len(got) != 1 || got[0].Name != "curl" {
+		t.Errorf("Expected only curl in formulae, got %+v", got)
+	}
+}
+
+func TestFilterAnd(t *testing.T) {
+	root, err := brewls.ParseFilter("root")
+	if err != nil {
+		t.Fatalf("ParseFilter returned error: %v", err)
+	}
+	outdated, err := brewls.ParseFilter(`outdated || type == "cask"`)
+	if err != nil {
+		t.Fatalf("ParseFilter returned error: %v", err)
+	}
+
+	both := root.And(outdated)
+	var names []string
+	for _, row := range filterTestRows() {
+		if both.Match(row) {
+			names = append(names, row.Name)
+		}
+	}
+	if want := []string{"terraform", "firefox"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+	if !both.UsesColumn("root") || !both.UsesColumn("type") {
+		t.Errorf("Expected the combined filter to use the columns of both")
+	}
+	if want := `(root) && (outdated || type == "cask")`; both.String() != want {
+		t.Errorf("Expected %q, got %q", want, both.String())
+	}
+
+	var none *brewls.Filter
+	if none.And(root) != root || root.And(nil) != root {
+		t.Errorf("Expected a nil filter to leave the other unchanged")
+	}
+}
@@ -0,0 +1,212 @@
+package brewls
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// InstalledDependencies maps every installed formula to the installed packages it
+// depends on directly, sorted. It is the forward counterpart of the InstalledBy
+// lists filled in by BuildReverseDependencyGraph; casks have no entries.
+func InstalledDependencies(info *BrewInfo) map[string][]string {
+	installed := make(map[string]struct{}, len(info.Formulae)+len(info.Casks))
+	for _, f := range info.Formulae {
+		installed[f.Name] = struct{}{}
+	}
+	for _, c := range info.Casks {
+		installed[c.Token] = struct{}{}
+	}
+
+	deps := make(map[string][]string, len(info.Formulae))
+	for _, f := range info.Formulae {
+		var direct []string
+		for _, dep := range f.AllDependencies() {
+			if _, ok := installed[dep]; ok {
+				direct = append(direct, dep)
+			}
+		}
+		deps[f.Name] = direct
+	}
+	return deps
+}
+
+// TransitiveClosure returns every name reachable from start through edges, sorted.
+// The start names themselves are only included when reachable from another start name.
+// Cycles are tolerated.
+func TransitiveClosure(edges map[string][]string, start []string) []string {
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		for _, next := range edges[name] {
+			if !seen[next] {
+				seen[next] = true
+				visit(next)
+			}
+		}
+	}
+	for _, name := range start {
+		visit(name)
+	}
+
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// WriteDependencyTree prints the installed dependency tree below each of the named
+// formulae, one indented level per dependency. When names is empty, every formula
+// that no other installed package depends on is used. maxDepth limits how many
+// levels are printed; 0 means unlimited. Dependency cycles are cut and marked.
+func WriteDependencyTree(writer io.Writer, info *BrewInfo, names []string, maxDepth int) error {
+	deps := InstalledDependencies(info)
+	roots := make(map[string]bool)
+	for _, f := range info.Formulae {
+		roots[f.Name] = f.IsRoot
+	}
+
+	if len(names) == 0 {
+		for _, f := range info.Formulae {
+			if len(f.InstalledBy) == 0 {
+				names = append(names, f.Name)
+			}
+		}
+	}
+
+	var b strings.Builder
+	var walk func(name, prefix string, depth int, path map[string]bool)
+	walk = func(name, prefix string, depth int, path map[string]bool) {
+		children := deps[name]
+		if maxDepth > 0 && depth >= maxDepth {
+			return
+		}
+		for i, child := range children {
+			branch, indent := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			if path[child] {
+				fmt.Fprintf(&b, "%s%s%s (cycle)\n", prefix, branch, child)
+				continue
+			}
+			fmt.Fprintf(&b, "%s%s%s\n", prefix, branch, child)
+			path[child] = true
+			walk(child, prefix+indent, depth+1, path)
+			delete(path, child)
+		}
+	}
+
+	for _, name := range names {
+		label := name
+		if roots[name] {
+			label += rootMarker
+		}
+		fmt.Fprintln(&b, label)
+		walk(name, "", 0, map[string]bool{name: true})
+	}
+
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+// DependencyPaths returns every chain of installed packages leading to target,
+// starting from a package nothing else depends on and ending with target itself.
+// A target that nothing depends on yields a single one-element path.
+func DependencyPaths(info *BrewInfo, target string) [][]string {
+	installedBy := make(map[string][]string)
+	for _, f := range info.Formulae {
+		installedBy[f.Name] = f.InstalledBy
+	}
+	for _, c := range info.Casks {
+		installedBy[c.Token] = c.InstalledBy
+	}
+
+	var paths [][]string
+	var climb func(name string, chain []string, seen map[string]bool)
+	climb = func(name string, chain []string, seen map[string]bool) {
+		chain = append([]string{name}, chain...)
+		parents := installedBy[name]
+		if len(parents) == 0 {
+			paths = append(paths, chain)
+			return
+		}
+		for _, parent := range parents {
+			if seen[parent] {
+				continue // Cycle; the chain is reported through its other parents
+			}
+			seen[parent] = true
+			climb(parent, chain, seen)
+			delete(seen, parent)
+		}
+	}
+	climb(target, nil, map[string]bool{target: true})
+
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], " ") < strings.Join(paths[j], " ")
+	})
+	return paths
+}
+
+// WriteDOT writes the installed dependency graph in Graphviz DOT format.
+// Edges point from a package to its dependency; root packages are drawn bold.
+func WriteDOT(writer io.Writer, info *BrewInfo) error {
+	var b strings.Builder
+	b.WriteString("digraph brewls {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, f := range info.Formulae {
+		if f.IsRoot {
+			fmt.Fprintf(&b, "  %q [style=bold];\n", f.Name)
+		} else {
+			fmt.Fprintf(&b, "  %q;\n", f.Name)
+		}
+	}
+	for _, c := range info.Casks {
+		fmt.Fprintf(&b, "  %q [shape=component];\n", c.Token)
+	}
+	deps := InstalledDependencies(info)
+	for _, f := range info.Formulae {
+		for _, dep := range deps[f.Name] {
+			fmt.Fprintf(&b, "  %q -> %q;\n", f.Name, dep)
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+// WriteMermaid writes the installed dependency graph as a Mermaid flowchart.
+func WriteMermaid(writer io.Writer, info *BrewInfo) error {
+	ids := make(map[string]string)
+	id := func(name string) string {
+		if _, ok := ids[name]; !ok {
+			ids[name] = fmt.Sprintf("n%d", len(ids))
+		}
+		return ids[name]
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, f := range info.Formulae {
+		label := f.Name
+		if f.IsRoot {
+			label += rootMarker
+		}
+		fmt.Fprintf(&b, "  %s[%q]\n", id(f.Name), label)
+	}
+	for _, c := range info.Casks {
+		fmt.Fprintf(&b, "  %s[%q]\n", id(c.Token), c.Token)
+	}
+	deps := InstalledDependencies(info)
+	for _, f := range info.Formulae {
+		for _, dep := range deps[f.Name] {
+			fmt.Fprintf(&b, "  %s --> %s\n", id(f.Name), id(dep))
+		}
+	}
+
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
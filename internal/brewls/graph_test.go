@@ -0,0 +1,93 @@
+package brewls_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestWriteDependencyTree(t *testing.T) {
+	var buf bytes.Buffer
+	if err := brewls.WriteDependencyTree(&buf, selectionTestInfo(), nil, 0); err != nil {
+		t.Fatalf("WriteDependencyTree returned error: %v", err)
+	}
+
+	expected := `python@3.12 *
+├── openssl@3
+│   └── ca-certificates
+└── sqlite
+python@3.13
+└── openssl@3
+    └── ca-certificates
+terraform *
+`
+	if buf.String() != expected {
+		t.Errorf("Expected tree:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteDependencyTreeDepthAndCycles(t *testing.T) {
+	info := &brewls.BrewInfo{Formulae: []brewls.Formula{
+		{Name: "a", Dependencies: []string{"b"}, Installed: []brewls.Installed{{Version: "1"}}},
+		{Name: "b", Dependencies: []string{"a"}, Installed: []brewls.Installed{{Version: "1"}}},
+	}}
+	brewls.BuildReverseDependencyGraph(info)
+
+	var buf bytes.Buffer
+	if err := brewls.WriteDependencyTree(&buf, info, []string{"a"}, 0); err != nil {
+		t.Fatalf("WriteDependencyTree returned error: %v", err)
+	}
+	if expected := "a\n└── b\n    └── a (cycle)\n"; buf.String() != expected {
+		t.Errorf("Expected cycle to be cut:\n%s\nGot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := brewls.WriteDependencyTree(&buf, selectionTestInfo(), []string{"python@3.12"}, 1); err != nil {
+		t.Fatalf("WriteDependencyTree returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "ca-certificates") {
+		t.Errorf("Expected depth 1 to stop before ca-certificates, got:\n%s", buf.String())
+	}
+}
+
+func TestDependencyPaths(t *testing.T) {
+	info := selectionTestInfo()
+
+	expected := [][]string{
+		{"python@3.12", "openssl@3", "ca-certificates"},
+		{"python@3.13", "openssl@3", "ca-certificates"},
+	}
+	if got := brewls.DependencyPaths(info, "ca-certificates"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected paths %v, got %v", expected, got)
+	}
+	if got, want := brewls.DependencyPaths(info, "terraform"), [][]string{{"terraform"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := brewls.WriteDOT(&buf, sampleBrewInfo()); err != nil {
+		t.Fatalf("WriteDOT returned error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"digraph brewls {", `"git" [style=bold];`, `"firefox" [shape=component];`, `"git" -> "pcre2";`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected DOT output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := brewls.WriteMermaid(&buf, sampleBrewInfo()); err != nil {
+		t.Fatalf("WriteMermaid returned error: %v", err)
+	}
+	expected := "graph LR\n  n0[\"git *\"]\n  n1[\"pcre2\"]\n  n2[\"firefox\"]\n  n0 --> n1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,222 @@
+package brewls
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kinds of change recorded in the history.
+const (
+	HistoryInstalled   = "installed"
+	HistoryUpgraded    = "upgraded" // the installed version changed
+	HistoryRemoved     = "removed"
+	HistoryRequested   = "requested"   // now installed on request
+	HistoryUnrequested = "unrequested" // now installed only as a dependency
+)
+
+// HistoryEvent is one observed change to an installed package.
+type HistoryEvent struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"` // PackageTypeFormula or PackageTypeCask
+	Name   string    `json:"name"`
+	Change string    `json:"change"`
+	From   string    `json:"from,omitempty"` // previous version, for upgraded and removed
+	To     string    `json:"to,omitempty"`   // new version, for installed and upgraded
+	// Requested is set on installed events for packages installed on request.
+	Requested bool `json:"requested,omitempty"`
+}
+
+// HistoryPath returns the history file, history.jsonl under DataDir.
+func HistoryPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// LoadHistory reads the events of a history file in the order they were
+// recorded. A missing file has no events.
+func LoadHistory(path string) ([]HistoryEvent, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []HistoryEvent
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var event HistoryEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("history %s: line %d: %w", path, n, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// historyState is what the history says is installed, by type and name.
+type historyState struct {
+	Version   string
+	Requested bool
+}
+
+func replayHistory(events []HistoryEvent) map[string]historyState {
+	state := make(map[string]historyState)
+	for _, e := range events {
+		key := e.Type + " " + e.Name
+		s := state[key]
+		switch e.Change {
+		case HistoryInstalled:
+			s = historyState{Version: e.To, Requested: e.Requested}
+		case HistoryUpgraded:
+			s.Version = e.To
+		case HistoryRequested:
+			s.Requested = true
+		case HistoryUnrequested:
+			s.Requested = false
+		case HistoryRemoved:
+			delete(state, key)
+			continue
+		}
+		state[key] = s
+	}
+	return state
+}
+
+// HistoryChanges returns the events that bring the history up to date with
+// info, sorted by time. Installs and upgrades are dated with the install time
+// brew reports when it has one; everything else with now.
+func HistoryChanges(history []HistoryEvent, info *BrewInfo, now time.Time) []HistoryEvent {
+	known := replayHistory(history)
+	var events []HistoryEvent
+	seen := make(map[string]bool)
+
+	observe := func(typ, name, version string, requested bool, installedAt int64) {
+		key := typ + " " + name
+		seen[key] = true
+		at := now
+		if installedAt > 0 {
+			at = time.Unix(installedAt, 0).UTC()
+		}
+		previous, ok := known[key]
+		switch {
+		case !ok:
+			events = append(events, HistoryEvent{Time: at, Type: typ, Name: name, Change: HistoryInstalled, To: version, Requested: requested})
+			return
+		case previous.Version != version:
+			events = append(events, HistoryEvent{Time: at, Type: typ, Name: name, Change: HistoryUpgraded, From: previous.Version, To: version})
+		}
+		if previous.Requested != requested {
+			change := HistoryUnrequested
+			if requested {
+				change = HistoryRequested
+			}
+			events = append(events, HistoryEvent{Time: now, Type: typ, Name: name, Change: change})
+		}
+	}
+	for _, f := range info.Formulae {
+		var installedAt int64
+		if len(f.Installed) > 0 {
+			installedAt = f.Installed[len(f.Installed)-1].Time
+		}
+		observe(PackageTypeFormula, f.Name, f.InstalledVersion(), f.InstalledOnRequest(), installedAt)
+	}
+	for _, c := range info.Casks {
+		observe(PackageTypeCask, c.Token, c.Installed, true, c.InstalledTime)
+	}
+
+	for key, s := range known {
+		if !seen[key] {
+			typ, name, _ := strings.Cut(key, " ")
+			events = append(events, HistoryEvent{Time: now, Type: typ, Name: name, Change: HistoryRemoved, From: s.Version})
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b HistoryEvent) int {
+		if c := a.Time.Compare(b.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return events
+}
+
+// AppendHistory appends events to the history file, creating it as needed.
+func AppendHistory(path string, events []HistoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(file, b.String()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// RecordHistory appends the changes between the history file at path and info,
+// and returns them.
+func RecordHistory(path string, info *BrewInfo, now time.Time) ([]HistoryEvent, error) {
+	history, err := LoadHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	events := HistoryChanges(history, info, now)
+	return events, AppendHistory(path, events)
+}
+
+// WriteHistory prints events as a timeline, one per line, e.g.
+// "2026-10-19 09:12  upgraded     formula node 20.11.0 → 22.3.0".
+func WriteHistory(writer io.Writer, events []HistoryEvent) error {
+	var b strings.Builder
+	for _, e := range events {
+		detail := e.To
+		switch e.Change {
+		case HistoryUpgraded:
+			detail = e.From + " → " + e.To
+		case HistoryRemoved:
+			detail = e.From
+		}
+		fmt.Fprintf(&b, "%s  %-11s  %s %s", e.Time.Local().Format("2006-01-02 15:04"), e.Change, e.Type, e.Name)
+		if detail != "" {
+			b.WriteString(" " + detail)
+		}
+		if e.Requested {
+			b.WriteString(" (on request)")
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
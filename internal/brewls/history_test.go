@@ -0,0 +1,85 @@
+package brewls_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func TestHistoryChanges(t *testing.T) {
+	installedAt := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	info := sampleBrewInfo()
+	info.Formulae[0].Installed[0].Time = installedAt.Unix()
+	first := brewls.HistoryChanges(nil, info, now)
+	expected := []brewls.HistoryEvent{
+		{Time: installedAt, Type: "formula", Name: "git", Change: brewls.HistoryInstalled, To: "2.44.0", Requested: true},
+		{Time: now, Type: "cask", Name: "firefox", Change: brewls.HistoryInstalled, To: "125.0", Requested: true},
+		{Time: now, Type: "formula", Name: "pcre2", Change: brewls.HistoryInstalled, To: "10.42"},
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, first)
+	}
+	if again := brewls.HistoryChanges(first, info, now); len(again) != 0 {
+		t.Errorf("Expected no changes for the same packages, got %+v", again)
+	}
+
+	later := now.Add(time.Hour)
+	info.Formulae[0].Installed[0].Version = "2.45.0"
+	info.Formulae[0].Installed[0].Time = 0
+	info.Formulae[1].Installed[0].InstalledOnRequest = true
+	info.Casks = nil
+	expected = []brewls.HistoryEvent{
+		{Time: later, Type: "cask", Name: "firefox", Change: brewls.HistoryRemoved, From: "125.0"},
+		{Time: later, Type: "formula", Name: "git", Change: brewls.HistoryUpgraded, From: "2.44.0", To: "2.45.0"},
+		{Time: later, Type: "formula", Name: "pcre2", Change: brewls.HistoryRequested},
+	}
+	if changes := brewls.HistoryChanges(first, info, later); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "history.jsonl")
+	now := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	recorded, err := brewls.RecordHistory(path, sampleBrewInfo(), now)
+	if err != nil || len(recorded) != 3 {
+		t.Fatalf("Expected 3 recorded events, got %d (%v)", len(recorded), err)
+	}
+	if again, err := brewls.RecordHistory(path, sampleBrewInfo(), now); err != nil || len(again) != 0 {
+		t.Errorf("Expected nothing new to record, got %+v (%v)", again, err)
+	}
+	loaded, err := brewls.LoadHistory(path)
+	if err != nil || !reflect.DeepEqual(loaded, recorded) {
+		t.Errorf("Expected the recorded events back, got %+v (%v)", loaded, err)
+	}
+
+	if events, err := brewls.LoadHistory(filepath.Join(t.TempDir(), "missing.jsonl")); err != nil || events != nil {
+		t.Errorf("Expected a missing history to be empty, got %+v (%v)", events, err)
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	at := time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local)
+	events := []brewls.HistoryEvent{
+		{Time: at, Type: "formula", Name: "git", Change: brewls.HistoryInstalled, To: "2.44.0", Requested: true},
+		{Time: at, Type: "formula", Name: "git", Change: brewls.HistoryUpgraded, From: "2.44.0", To: "2.45.0"},
+		{Time: at, Type: "formula", Name: "git", Change: brewls.HistoryUnrequested},
+	}
+	var buf bytes.Buffer
+	if err := brewls.WriteHistory(&buf, events); err != nil {
+		t.Fatalf("WriteHistory returned error: %v", err)
+	}
+	expected := "2026-10-01 09:30  installed    formula git 2.44.0 (on request)\n" +
+		"2026-10-01 09:30  upgraded     formula git 2.44.0 → 2.45.0\n" +
+		"2026-10-01 09:30  unrequested  formula git\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,98 @@
+package brewls
+
+import (
+	"html/template"
+	"io"
+)
+
+// HTMLRenderer writes a self-contained HTML report with sortable, filterable tables.
+// Styles and scripts are inlined so the file can be shared on its own.
+type HTMLRenderer struct{}
+
+type htmlSection struct {
+	Title  string
+	Header []string
+	Rows   []htmlRow
+}
+
+type htmlRow struct {
+	Cells      []string
+	Root       bool
+	NameColumn int // index of the cell that gets the root badge, or -1
+}
+
+// Render implements Renderer.
+func (HTMLRenderer) Render(view *View, writer io.Writer) error {
+	var sections []htmlSection
+	for _, section := range view.Sections() {
+		rows := make([]htmlRow, len(section.Rows))
+		for i, row := range section.Rows {
+			rows[i] = htmlRow{Cells: view.Cells(row, DefaultListSeparator), Root: row.IsRoot, NameColumn: view.NameColumn()}
+		}
+		sections = append(sections, htmlSection{Title: section.Title, Header: view.Header(), Rows: rows})
+	}
+	return htmlReportTemplate.Execute(writer, sections)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>brewls report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.35rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th[aria-sort="ascending"]::after { content: " \25B2"; }
+th[aria-sort="descending"]::after { content: " \25BC"; }
+input.filter { margin: 0 0 0.5rem; padding: 0.3rem 0.5rem; width: 20rem; }
+.badge { display: inline-block; margin-left: 0.4rem; padding: 0 0.45rem; border-radius: 1rem; background: #1f883d; color: #fff; font-size: 0.75rem; }
+</style>
+</head>
+<body>
+<h1>brewls report</h1>
+{{- range .}}
+<section>
+<h2>{{.Title}}</h2>
+<input class="filter" type="search" placeholder="Filter {{.Title}}">
+<table>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range $row := .Rows}}
+<tr>{{range $i, $cell := $row.Cells}}<td data-value="{{$cell}}">{{$cell}}{{if and (eq $i $row.NameColumn) $row.Root}}<span class="badge">root</span>{{end}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+</section>
+{{- end}}
+<script>
+document.querySelectorAll("section").forEach(function (section) {
+  var table = section.querySelector("table");
+  var body = table.tBodies[0];
+  section.querySelector("input.filter").addEventListener("input", function (e) {
+    var needle = e.target.value.toLowerCase();
+    Array.prototype.forEach.call(body.rows, function (row) {
+      row.hidden = needle !== "" && row.textContent.toLowerCase().indexOf(needle) === -1;
+    });
+  });
+  table.querySelectorAll("th").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var ascending = th.getAttribute("aria-sort") !== "ascending";
+      table.querySelectorAll("th").forEach(function (other) { other.removeAttribute("aria-sort"); });
+      th.setAttribute("aria-sort", ascending ? "ascending" : "descending");
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[column].dataset.value || a.cells[column].textContent;
+        var y = b.cells[column].dataset.value || b.cells[column].textContent;
+        var cmp = x.localeCompare(y, undefined, { numeric: true });
+        return ascending ? cmp : -cmp;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
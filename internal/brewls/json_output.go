@@ -0,0 +1,107 @@
+package brewls
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSONSchemaVersion is the version of the document written by JSONRenderer.
+// It is bumped whenever a field is removed or changes meaning; new optional
+// fields may be added without a bump.
+const JSONSchemaVersion = 1
+
+// Package types reported in machine-readable output.
+const (
+	PackageTypeFormula = "formula"
+	PackageTypeCask    = "cask"
+)
+
+// JSONDocument is the top-level document written by JSONRenderer.
+// Its shape is described by docs/brewls.schema.json.
+type JSONDocument struct {
+	SchemaVersion int           `json:"schema_version"`
+	Formulae      []JSONPackage `json:"formulae"`
+	Casks         []JSONPackage `json:"casks"`
+}
+
+// JSONPackage is a single formula or cask in machine-readable output.
+type JSONPackage struct {
+	Name        string     `json:"name"`
+	Version     string     `json:"version"`
+	InstalledBy []string   `json:"installed_by"`
+	IsRoot      bool       `json:"is_root"`
+	Type        string     `json:"type"`
+	DisplayName string     `json:"display_name,omitempty"`
+	FullName    string     `json:"full_name,omitempty"`
+	Tap         string     `json:"tap,omitempty"`
+	Desc        string     `json:"desc,omitempty"`
+	Homepage    string     `json:"homepage,omitempty"`
+	License     string     `json:"license,omitempty"`
+	Outdated    bool       `json:"outdated,omitempty"`
+	Deprecated  bool       `json:"deprecated,omitempty"`
+	Disabled    bool       `json:"disabled,omitempty"`
+	InstalledAt *time.Time `json:"installed_at,omitempty"`
+	SizeBytes   int64      `json:"size_bytes,omitempty"`
+}
+
+// NewJSONDocument converts a View into the versioned JSON document.
+func NewJSONDocument(view *View) JSONDocument {
+	doc := JSONDocument{
+		SchemaVersion: JSONSchemaVersion,
+		Formulae:      make([]JSONPackage, 0, len(view.Formulae)),
+		Casks:         make([]JSONPackage, 0, len(view.Casks)),
+	}
+	for _, row := range view.Formulae {
+		doc.Formulae = append(doc.Formulae, newJSONPackage(row))
+	}
+	for _, row := range view.Casks {
+		doc.Casks = append(doc.Casks, newJSONPackage(row))
+	}
+	return doc
+}
+
+func newJSONPackage(row Row) JSONPackage {
+	pkg := JSONPackage{
+		Name:        row.Name,
+		Version:     row.Version,
+		InstalledBy: UniqueAndSortStrings(row.InstalledBy),
+		IsRoot:      row.IsRoot,
+		Type:        row.Type,
+		FullName:    row.FullName,
+		Tap:         row.Tap,
+		Desc:        row.Desc,
+		Homepage:    row.Homepage,
+		License:     row.License,
+		Outdated:    row.Outdated,
+		Deprecated:  row.Deprecated,
+		Disabled:    row.Disabled,
+	}
+	if row.DisplayName != row.Name {
+		pkg.DisplayName = row.DisplayName
+	}
+	if !row.InstalledAt.IsZero() {
+		installedAt := row.InstalledAt.UTC()
+		pkg.InstalledAt = &installedAt
+	}
+	pkg.SizeBytes = row.SizeBytes
+	return pkg
+}
+
+// writeJSON writes every formula and cask as a single indented JSON document.
+func writeJSON(view *View, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJSONDocument(view))
+}
+
+// writeJSONLines writes one JSON package object per line, formulae first, for piping into jq.
+func writeJSONLines(view *View, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	for _, row := range view.Rows() {
+		if err := encoder.Encode(newJSONPackage(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
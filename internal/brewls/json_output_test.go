@@ -0,0 +1,163 @@
+package brewls_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func sampleBrewInfo() *brewls.BrewInfo {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{
+				Name: "git",
+				Tap:  "homebrew/core",
+				Installed: []brewls.Installed{
+					{Version: "2.44.0", InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "pcre2"}}},
+				},
+			},
+			{
+				Name:      "pcre2",
+				Tap:       "homebrew/core",
+				Outdated:  true,
+				Installed: []brewls.Installed{{Version: "10.42"}},
+			},
+		},
+		Casks: []brewls.Cask{
+			{Token: "firefox", Name: []string{"Mozilla Firefox"}, Installed: "125.0"},
+		},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func TestJSONRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (brewls.JSONRenderer{}).Render(brewls.NewView(sampleBrewInfo(), brewls.ViewOptions{}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	var doc brewls.JSONDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	expected := brewls.JSONDocument{
+		SchemaVersion: brewls.JSONSchemaVersion,
+		Formulae: []brewls.JSONPackage{
+			{Name: "git", Version: "2.44.0", InstalledBy: []string{}, IsRoot: true, Type: "formula", Tap: "homebrew/core"},
+			{Name: "pcre2", Version: "10.42", InstalledBy: []string{"git"}, Type: "formula", Tap: "homebrew/core", Outdated: true},
+		},
+		Casks: []brewls.JSONPackage{
+			{Name: "firefox", Version: "125.0", InstalledBy: []string{}, IsRoot: true, Type: "cask", DisplayName: "Mozilla Firefox"},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("Expected document %+v, got %+v", expected, doc)
+	}
+}
+
+func TestJSONRendererEmptyListsAreArrays(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (brewls.JSONRenderer{}).Render(brewls.NewView(&brewls.BrewInfo{}, brewls.ViewOptions{}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"formulae": []`) || !strings.Contains(buf.String(), `"casks": []`) {
+		t.Errorf("Expected empty arrays rather than null, got:\n%s", buf.String())
+	}
+}
+
+func TestJSONRendererLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (brewls.JSONRenderer{Lines: true}).Render(brewls.NewView(sampleBrewInfo(), brewls.ViewOptions{}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d:\n%s", len(lines), buf.String())
+	}
+
+	var names []string
+	for _, line := range lines {
+		var pkg brewls.JSONPackage
+		if err := json.Unmarshal([]byte(line), &pkg); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", line, err)
+		}
+		names = append(names, pkg.Type+"/"+pkg.Name)
+	}
+	if want := []string{"formula/git", "formula/pcre2", "cask/firefox"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+// TestJSONSchemaMatchesOutput keeps docs/brewls.schema.json in sync with the emitted fields.
+func TestJSONSchemaMatchesOutput(t *testing.T) {
+	raw, err := os.ReadFile("../../docs/brewls.schema.json")
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+
+	var schema struct {
+		schemaObject
+		Defs struct {
+			Package schemaObject `json:"package"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	info := sampleBrewInfo()
+	info.Formulae[0].FullName = "git"
+	info.Formulae[0].Desc = "Distributed revision control system"
+	info.Formulae[0].Homepage = "https://git-scm.com"
+	info.Formulae[0].Deprecated = true
+	info.Formulae[0].Disabled = true
+	info.Formulae[0].Installed[0].Time = 1700000000
+	info.Formulae[0].SizeBytes = 2048
+
+	var buf bytes.Buffer
+	if err := (brewls.JSONRenderer{}).Render(brewls.NewView(info, brewls.ViewOptions{}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	checkKeys(t, "document", doc, schema.schemaObject)
+
+	for _, section := range []string{"formulae", "casks"} {
+		var pkgs []map[string]json.RawMessage
+		if err := json.Unmarshal(doc[section], &pkgs); err != nil {
+			t.Fatalf("%s is not an array of objects: %v", section, err)
+		}
+		for _, pkg := range pkgs {
+			checkKeys(t, section, pkg, schema.Defs.Package)
+		}
+	}
+}
+
+type schemaObject struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+func checkKeys(t *testing.T, where string, got map[string]json.RawMessage, schema schemaObject) {
+	t.Helper()
+	for _, key := range schema.Required {
+		if _, ok := got[key]; !ok {
+			t.Errorf("%s: required key %q missing from output", where, key)
+		}
+	}
+	for key := range got {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("%s: key %q is not described by the schema", where, key)
+		}
+	}
+}
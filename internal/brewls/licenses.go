@@ -0,0 +1,419 @@
+package brewls
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Copyleft strengths reported for licenses.
+const (
+	CopyleftNone   = ""
+	CopyleftWeak   = "weak"   // file or library scoped, e.g. LGPL, MPL, or GPL with a linking exception
+	CopyleftStrong = "strong" // covers derived works as a whole, e.g. GPL, AGPL
+)
+
+var copyleftRanks = map[string]int{CopyleftNone: 0, CopyleftWeak: 1, CopyleftStrong: 2}
+
+// License identifier prefixes by copyleft strength. More specific prefixes
+// such as LGPL- are checked before GPL- would match them. Every EUPL version
+// is weak: it does not reach works that only link to the licensed one.
+var (
+	weakCopyleftPrefixes   = []string{"LGPL-", "MPL-", "EPL-", "CDDL-", "CPL-", "MS-RL", "APSL-", "EUPL-"}
+	strongCopyleftPrefixes = []string{"GPL-", "AGPL-", "SSPL-", "OSL-", "CC-BY-SA-", "GFDL-", "Sleepycat", "RPL-"}
+)
+
+// licenseCopyleft returns the copyleft strength of one SPDX license
+// identifier, with an optional WITH exception, which weakens strong copyleft.
+func licenseCopyleft(id, exception string) string {
+	for _, prefix := range weakCopyleftPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return CopyleftWeak
+		}
+	}
+	for _, prefix := range strongCopyleftPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			if exception != "" {
+				return CopyleftWeak
+			}
+			return CopyleftStrong
+		}
+	}
+	return CopyleftNone
+}
+
+// licenseTerm is a parsed SPDX license expression: either a single license,
+// or the AND or OR of its operands.
+type licenseTerm struct {
+	operator  string // "AND", "OR", or "" for a single license
+	id        string
+	exception string // after WITH
+	operands  []*licenseTerm
+}
+
+// String returns the license as written in an expression, e.g.
+// "GPL-2.0-only WITH Classpath-exception-2.0".
+func (t *licenseTerm) String() string {
+	if t.exception != "" {
+		return t.id + " WITH " + t.exception
+	}
+	return t.id
+}
+
+// parseLicense parses a license as brew reports it. Licenses that are not
+// SPDX expressions, such as "Public Domain", become a single license.
+func parseLicense(license string) *licenseTerm {
+	expression, ok := SPDXExpression(license)
+	if !ok {
+		return &licenseTerm{id: strings.TrimSpace(license)}
+	}
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+	p := &licenseParser{tokens: tokens}
+	term := p.or()
+	if term == nil || p.pos != len(tokens) {
+		return &licenseTerm{id: expression} // unbalanced parentheses; keep it whole
+	}
+	return term
+}
+
+// licenseParser is a recursive descent parser for SPDX expressions, where AND
+// binds tighter than OR.
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseParser) or() *licenseTerm {
+	return p.binary("OR", p.and)
+}
+
+func (p *licenseParser) and() *licenseTerm {
+	return p.binary("AND", p.atom)
+}
+
+func (p *licenseParser) binary(operator string, operand func() *licenseTerm) *licenseTerm {
+	first := operand()
+	if first == nil || p.peek() != operator {
+		return first
+	}
+	term := &licenseTerm{operator: operator, operands: []*licenseTerm{first}}
+	for p.peek() == operator {
+		p.pos++
+		next := operand()
+		if next == nil {
+			return nil
+		}
+		term.operands = append(term.operands, next)
+	}
+	return term
+}
+
+func (p *licenseParser) atom() *licenseTerm {
+	switch token := p.peek(); token {
+	case "", ")", "AND", "OR", "WITH":
+		return nil
+	case "(":
+		p.pos++
+		term := p.or()
+		if term == nil || p.peek() != ")" {
+			return nil
+		}
+		p.pos++
+		return term
+	default:
+		p.pos++
+		term := &licenseTerm{id: token}
+		if p.peek() == "WITH" {
+			p.pos++
+			term.exception = p.peek()
+			if term.exception == "" {
+				return nil
+			}
+			p.pos++
+		}
+		return term
+	}
+}
+
+// copyleft returns the copyleft strength of the whole term: a choice between
+// licenses (OR) is only as strong as the weakest, and licenses that all apply
+// (AND) as the strongest.
+func (t *licenseTerm) copyleft() string {
+	if t.operator == "" {
+		return licenseCopyleft(t.id, t.exception)
+	}
+	result := t.operands[0].copyleft()
+	for _, operand := range t.operands[1:] {
+		c := operand.copyleft()
+		if t.operator == "OR" && copyleftRanks[c] < copyleftRanks[result] || t.operator == "AND" && copyleftRanks[c] > copyleftRanks[result] {
+			result = c
+		}
+	}
+	return result
+}
+
+// satisfied reports whether the licenses accepted by accept can meet the term,
+// and returns the single licenses that were rejected.
+func (t *licenseTerm) satisfied(accept func(*licenseTerm) bool) (bool, []*licenseTerm) {
+	if t.operator == "" {
+		if accept(t) {
+			return true, nil
+		}
+		return false, []*licenseTerm{t}
+	}
+	var rejected []*licenseTerm
+	for _, operand := range t.operands {
+		ok, r := operand.satisfied(accept)
+		if ok && t.operator == "OR" {
+			return true, nil
+		}
+		if !ok {
+			if t.operator == "AND" {
+				return false, r
+			}
+			rejected = append(rejected, r...)
+		}
+	}
+	return t.operator == "AND", rejected
+}
+
+// LicensePolicy lists the licenses legal allows or denies. Entries are SPDX
+// identifiers or globs such as "GPL-*", matched case-insensitively against
+// each license of an expression, with or without its WITH exception.
+type LicensePolicy struct {
+	// Allow, when not empty, is the only licenses packages may use; packages
+	// without a known license are then reported too.
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// Empty reports whether the policy allows everything.
+func (p LicensePolicy) Empty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// Validate reports malformed glob patterns.
+func (p LicensePolicy) Validate() error {
+	for _, pattern := range slices.Concat(p.Allow, p.Deny) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid license pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func matchesLicense(patterns []string, t *licenseTerm) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		for _, name := range []string{t.id, t.String()} {
+			if ok, _ := path.Match(pattern, strings.ToLower(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check reports whether a package with license complies, and if not, why.
+// license is as brew reports it; "" means it is not known.
+func (p LicensePolicy) Check(license string) (bool, string) {
+	if strings.TrimSpace(license) == "" {
+		if len(p.Allow) > 0 {
+			return false, "no license known"
+		}
+		return true, ""
+	}
+	ok, rejected := parseLicense(license).satisfied(func(t *licenseTerm) bool {
+		return !matchesLicense(p.Deny, t) && (len(p.Allow) == 0 || matchesLicense(p.Allow, t))
+	})
+	if ok {
+		return true, ""
+	}
+	var denied, notAllowed []string
+	for _, t := range rejected {
+		if matchesLicense(p.Deny, t) {
+			denied = append(denied, t.String())
+		} else {
+			notAllowed = append(notAllowed, t.String())
+		}
+	}
+	var reasons []string
+	if len(denied) > 0 {
+		reasons = append(reasons, "denied: "+strings.Join(denied, ", "))
+	}
+	if len(notAllowed) > 0 {
+		reasons = append(reasons, "not allowed: "+strings.Join(notAllowed, ", "))
+	}
+	return false, strings.Join(reasons, "; ")
+}
+
+// LicensedPackage is an installed package with its license.
+type LicensedPackage struct {
+	Type     string `json:"type"` // PackageTypeFormula or PackageTypeCask
+	Name     string `json:"name"`
+	License  string `json:"license"` // SPDX expression, license name, or "" when unknown
+	Copyleft string `json:"copyleft,omitempty"`
+}
+
+// LicenseGroup lists the packages that share a license.
+type LicenseGroup struct {
+	License  string   `json:"license"` // "" for packages whose license is not known
+	Copyleft string   `json:"copyleft,omitempty"`
+	Packages []string `json:"packages"`
+}
+
+// RootCopyleft lists the copyleft packages a root package brings in, itself
+// included.
+type RootCopyleft struct {
+	Root     string            `json:"root"`
+	Packages []LicensedPackage `json:"packages"`
+}
+
+// LicenseViolation is a package whose license the policy does not accept.
+type LicenseViolation struct {
+	LicensedPackage
+	Reason string `json:"reason"`
+}
+
+// LicenseReport is the license inventory of an installation.
+type LicenseReport struct {
+	Groups     []LicenseGroup     `json:"groups"`
+	Copyleft   []RootCopyleft     `json:"copyleft_by_root"`
+	Policy     *LicensePolicy     `json:"policy,omitempty"`
+	Violations []LicenseViolation `json:"violations"`
+}
+
+// NewLicenseReport groups the installed packages by license, finds the
+// copyleft licenses among each root's dependencies and checks every package
+// against policy. brew reports no licenses for casks, so they are unknown.
+func NewLicenseReport(info *BrewInfo, policy LicensePolicy) LicenseReport {
+	report := LicenseReport{Groups: []LicenseGroup{}, Copyleft: []RootCopyleft{}, Violations: []LicenseViolation{}}
+	if !policy.Empty() {
+		report.Policy = &policy
+	}
+
+	packages := make(map[string]LicensedPackage, len(info.Formulae)+len(info.Casks))
+	var roots []string
+	add := func(pkg LicensedPackage, isRoot bool) {
+		if pkg.License != "" {
+			if expression, ok := SPDXExpression(pkg.License); ok {
+				pkg.License = expression
+			}
+			pkg.Copyleft = parseLicense(pkg.License).copyleft()
+		}
+		if _, ok := packages[pkg.Name]; !ok || pkg.Type == PackageTypeFormula {
+			packages[pkg.Name] = pkg
+		}
+		if isRoot {
+			roots = append(roots, pkg.Name)
+		}
+
+		i := slices.IndexFunc(report.Groups, func(g LicenseGroup) bool { return g.License == pkg.License })
+		if i < 0 {
+			report.Groups = append(report.Groups, LicenseGroup{License: pkg.License, Copyleft: pkg.Copyleft})
+			i = len(report.Groups) - 1
+		}
+		report.Groups[i].Packages = append(report.Groups[i].Packages, pkg.Name)
+
+		if ok, reason := policy.Check(pkg.License); !ok {
+			report.Violations = append(report.Violations, LicenseViolation{pkg, reason})
+		}
+	}
+	for _, f := range info.Formulae {
+		add(LicensedPackage{Type: PackageTypeFormula, Name: f.Name, License: f.License}, f.IsRoot)
+	}
+	for _, c := range info.Casks {
+		add(LicensedPackage{Type: PackageTypeCask, Name: c.Token}, c.IsRoot)
+	}
+
+	for i := range report.Groups {
+		slices.Sort(report.Groups[i].Packages)
+	}
+	slices.SortFunc(report.Groups, func(a, b LicenseGroup) int {
+		if (a.License == "") != (b.License == "") {
+			return boolCompare(a.License == "", b.License == "") // unknown last
+		}
+		return strings.Compare(strings.ToLower(a.License), strings.ToLower(b.License))
+	})
+	slices.SortFunc(report.Violations, func(a, b LicenseViolation) int { return strings.Compare(a.Name, b.Name) })
+
+	deps := InstalledDependencies(info)
+	slices.Sort(roots)
+	for _, root := range slices.Compact(roots) {
+		entry := RootCopyleft{Root: root}
+		for _, name := range slices.Concat([]string{root}, TransitiveClosure(deps, []string{root})) {
+			if pkg := packages[name]; pkg.Copyleft != CopyleftNone && !slices.Contains(entry.Packages, pkg) {
+				entry.Packages = append(entry.Packages, pkg)
+			}
+		}
+		if len(entry.Packages) > 0 {
+			report.Copyleft = append(report.Copyleft, entry)
+		}
+	}
+	return report
+}
+
+func boolCompare(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// WriteLicenseReport prints the license groups as a table, then the copyleft
+// packages reached from each root, then any policy violations.
+func WriteLicenseReport(writer io.Writer, report LicenseReport) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LICENSE\tCOPYLEFT\tCOUNT\tPACKAGES")
+	for _, g := range report.Groups {
+		license := g.License
+		if license == "" {
+			license = "(unknown)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", license, g.Copyleft, len(g.Packages), strings.Join(g.Packages, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if len(report.Copyleft) > 0 {
+		b.WriteString("\nCopyleft licenses in each root package and its dependencies:\n")
+		for _, root := range report.Copyleft {
+			var packages []string
+			for _, pkg := range root.Packages {
+				packages = append(packages, fmt.Sprintf("%s (%s, %s)", pkg.Name, pkg.License, pkg.Copyleft))
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", root.Root, strings.Join(packages, ", "))
+		}
+	}
+	if report.Policy != nil {
+		if len(report.Violations) == 0 {
+			b.WriteString("\nEvery package complies with the license policy.\n")
+		} else {
+			fmt.Fprintf(&b, "\nLicense policy violations: %d\n", len(report.Violations))
+			for _, v := range report.Violations {
+				license := v.License
+				if license == "" {
+					license = "unknown license"
+				}
+				fmt.Fprintf(&b, "  %s %s (%s): %s\n", v.Type, v.Name, license, v.Reason)
+			}
+		}
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
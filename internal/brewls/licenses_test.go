@@ -0,0 +1,153 @@
+package brewls_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func licensesTestInfo() *brewls.BrewInfo {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "git", License: "GPL-2.0-only", Installed: []brewls.Installed{{Version: "2.44.0", InstalledOnRequest: true,
+				RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "pcre2"}, {FullName: "gettext"}}}}},
+			{Name: "pcre2", License: "BSD-3-Clause", Installed: []brewls.Installed{{Version: "10.42"}}},
+			{Name: "gettext", License: "GPL-3.0-or-later", Installed: []brewls.Installed{{Version: "0.22.5"}}},
+			{Name: "ripgrep", License: "Unlicense or MIT", Installed: []brewls.Installed{{Version: "14.1.0", InstalledOnRequest: true}}},
+			{Name: "sqlite", License: "blessing", Installed: []brewls.Installed{{Version: "3.46.0", InstalledOnRequest: true,
+				RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "readline"}}}}},
+			{Name: "readline", License: "GPL-3.0-or-later", Installed: []brewls.Installed{{Version: "8.2.10"}}},
+			{Name: "tzdata", License: "Public Domain", Installed: []brewls.Installed{{Version: "2024a", InstalledOnRequest: true}}},
+		},
+		Casks: []brewls.Cask{{Token: "firefox", Installed: "125.0"}},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func TestLicensePolicyCheck(t *testing.T) {
+	tests := []struct {
+		policy  brewls.LicensePolicy
+		license string
+		ok      bool
+		reason  string
+	}{
+		{brewls.LicensePolicy{}, "GPL-3.0-only", true, ""},
+		{brewls.LicensePolicy{}, "", true, ""},
+		{brewls.LicensePolicy{Deny: []string{"GPL-*"}}, "GPL-3.0-only", false, "denied: GPL-3.0-only"},
+		{brewls.LicensePolicy{Deny: []string{"gpl-*"}}, "GPL-3.0-only", false, "denied: GPL-3.0-only"},
+		{brewls.LicensePolicy{Deny: []string{"GPL-*"}}, "LGPL-2.1-or-later", true, ""},
+		{brewls.LicensePolicy{Deny: []string{"GPL-*"}}, "GPL-2.0-only or MIT", true, ""},
+		{brewls.LicensePolicy{Deny: []string{"GPL-*"}}, "GPL-2.0-only and MIT", false, "denied: GPL-2.0-only"},
+		{brewls.LicensePolicy{Deny: []string{"GPL-2.0-only WITH Classpath-exception-2.0"}}, "GPL-2.0-only with Classpath-exception-2.0", false, "denied: GPL-2.0-only WITH Classpath-exception-2.0"},
+		{brewls.LicensePolicy{Allow: []string{"MIT", "BSD-*"}}, "BSD-3-Clause", true, ""},
+		{brewls.LicensePolicy{Allow: []string{"MIT", "BSD-*"}}, "Apache-2.0 or MIT", true, ""},
+		{brewls.LicensePolicy{Allow: []string{"MIT", "BSD-*"}}, "(MIT or GPL-2.0-only) and Zlib", false, "not allowed: Zlib"},
+		{brewls.LicensePolicy{Allow: []string{"MIT"}}, "Apache-2.0 or GPL-2.0-only", false, "not allowed: Apache-2.0, GPL-2.0-only"},
+		{brewls.LicensePolicy{Allow: []string{"MIT"}, Deny: []string{"GPL-*"}}, "Apache-2.0 or GPL-2.0-only", false, "denied: GPL-2.0-only; not allowed: Apache-2.0"},
+		{brewls.LicensePolicy{Allow: []string{"MIT"}}, "", false, "no license known"},
+		{brewls.LicensePolicy{Allow: []string{"Public Domain"}}, "Public Domain", true, ""},
+	}
+	for _, tt := range tests {
+		ok, reason := tt.policy.Check(tt.license)
+		if ok != tt.ok || reason != tt.reason {
+			t.Errorf("%+v.Check(%q) = %v, %q; expected %v, %q", tt.policy, tt.license, ok, reason, tt.ok, tt.reason)
+		}
+	}
+
+	if err := (brewls.LicensePolicy{Deny: []string{"GPL-["}}).Validate(); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+}
+
+func TestNewLicenseReport(t *testing.T) {
+	report := brewls.NewLicenseReport(licensesTestInfo(), brewls.LicensePolicy{})
+
+	expectedGroups := []brewls.LicenseGroup{
+		{License: "blessing", Packages: []string{"sqlite"}},
+		{License: "BSD-3-Clause", Packages: []string{"pcre2"}},
+		{License: "GPL-2.0-only", Copyleft: brewls.CopyleftStrong, Packages: []string{"git"}},
+		{License: "GPL-3.0-or-later", Copyleft: brewls.CopyleftStrong, Packages: []string{"gettext", "readline"}},
+		{License: "Public Domain", Packages: []string{"tzdata"}},
+		{License: "Unlicense OR MIT", Packages: []string{"ripgrep"}},
+		{License: "", Packages: []string{"firefox"}},
+	}
+	if !reflect.DeepEqual(report.Groups, expectedGroups) {
+		t.Errorf("Unexpected groups:\n%+v\nexpected\n%+v", report.Groups, expectedGroups)
+	}
+
+	gpl3 := brewls.LicensedPackage{Type: "formula", License: "GPL-3.0-or-later", Copyleft: brewls.CopyleftStrong}
+	git, gettext, readline := gpl3, gpl3, gpl3
+	git.Name, git.License, gettext.Name, readline.Name = "git", "GPL-2.0-only", "gettext", "readline"
+	expectedCopyleft := []brewls.RootCopyleft{
+		{Root: "git", Packages: []brewls.LicensedPackage{git, gettext}},
+		{Root: "sqlite", Packages: []brewls.LicensedPackage{readline}},
+	}
+	if !reflect.DeepEqual(report.Copyleft, expectedCopyleft) {
+		t.Errorf("Unexpected copyleft by root:\n%+v\nexpected\n%+v", report.Copyleft, expectedCopyleft)
+	}
+	if report.Policy != nil || len(report.Violations) != 0 {
+		t.Errorf("Expected no policy and no violations, got %+v %+v", report.Policy, report.Violations)
+	}
+}
+
+func TestLicenseCopyleft(t *testing.T) {
+	tests := map[string]string{
+		"MIT":               brewls.CopyleftNone,
+		"AGPL-3.0-only":     brewls.CopyleftStrong,
+		"LGPL-2.1-or-later": brewls.CopyleftWeak,
+		"MPL-2.0":           brewls.CopyleftWeak,
+		"EUPL-1.0":          brewls.CopyleftWeak,
+		"EUPL-1.1":          brewls.CopyleftWeak,
+		"EUPL-1.2":          brewls.CopyleftWeak,
+		"EUPL-1.2 and MIT":  brewls.CopyleftWeak,
+		"GPL-2.0-only with Classpath-exception-2.0": brewls.CopyleftWeak,
+		"GPL-2.0-or-later or MIT":                   brewls.CopyleftNone,
+		"LGPL-2.1-only or GPL-3.0-only":             brewls.CopyleftWeak,
+		"MIT and GPL-3.0-or-later":                  brewls.CopyleftStrong,
+		"(MIT or GPL-2.0-only) and MPL-2.0":         brewls.CopyleftWeak,
+		"MIT or GPL-2.0-only and AGPL-3.0-only":     brewls.CopyleftNone,
+	}
+	for license, expected := range tests {
+		info := &brewls.BrewInfo{Formulae: []brewls.Formula{{Name: "x", License: license, Installed: []brewls.Installed{{Version: "1"}}}}}
+		if got := brewls.NewLicenseReport(info, brewls.LicensePolicy{}).Groups[0].Copyleft; got != expected {
+			t.Errorf("Copyleft of %q = %q, expected %q", license, got, expected)
+		}
+	}
+}
+
+func TestWriteLicenseReport(t *testing.T) {
+	policy := brewls.LicensePolicy{Deny: []string{"GPL-3.0-*"}}
+	report := brewls.NewLicenseReport(licensesTestInfo(), policy)
+	if len(report.Violations) != 2 || report.Violations[0].Name != "gettext" || report.Violations[1].Reason != "denied: GPL-3.0-or-later" {
+		t.Fatalf("Unexpected violations: %+v", report.Violations)
+	}
+
+	var buf bytes.Buffer
+	if err := brewls.WriteLicenseReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"LICENSE           COPYLEFT  COUNT  PACKAGES\n",
+		"GPL-3.0-or-later  strong    2      gettext, readline\n",
+		"(unknown)                   1      firefox\n",
+		"  git: git (GPL-2.0-only, strong), gettext (GPL-3.0-or-later, strong)\n",
+		"License policy violations: 2\n",
+		"  formula readline (GPL-3.0-or-later): denied: GPL-3.0-or-later\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := brewls.WriteLicenseReport(&buf, brewls.NewLicenseReport(licensesTestInfo(), brewls.LicensePolicy{Deny: []string{"AGPL-*"}})); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "\nEvery package complies with the license policy.\n") {
+		t.Errorf("Expected compliance, got:\n%s", buf.String())
+	}
+}
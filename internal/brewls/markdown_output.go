@@ -0,0 +1,66 @@
+package brewls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MarkdownRenderer writes formulae and casks as GitHub-flavoured pipe tables under level-two headings.
+type MarkdownRenderer struct{}
+
+// Render implements Renderer.
+func (MarkdownRenderer) Render(view *View, writer io.Writer) error {
+	for i, section := range view.Sections() {
+		if i > 0 {
+			if _, err := fmt.Fprintln(writer); err != nil {
+				return err
+			}
+		}
+		if err := writeMarkdownSection(writer, view, section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMarkdownSection(writer io.Writer, view *View, section Section) error {
+	header := view.Header()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", section.Title)
+	writeMarkdownRow(&b, header)
+
+	divider := make([]string, len(header))
+	for i := range divider {
+		divider[i] = "---"
+	}
+	writeMarkdownRow(&b, divider)
+
+	for _, row := range section.Rows {
+		cells := view.Cells(row, DefaultListSeparator)
+		for i, cell := range cells {
+			cells[i] = escapeMarkdownCell(cell)
+		}
+		if i := view.NameColumn(); row.IsRoot && i >= 0 {
+			cells[i] += ` \*`
+		}
+		writeMarkdownRow(&b, cells)
+	}
+
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("| ")
+	b.WriteString(strings.Join(cells, " | "))
+	b.WriteString(" |\n")
+}
+
+// markdownEscaper escapes characters that would end a table cell or start emphasis.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, `*`, `\*`, "\n", " ")
+
+func escapeMarkdownCell(cell string) string {
+	return markdownEscaper.Replace(cell)
+}
@@ -0,0 +1,29 @@
+package brewls
+
+import (
+	"bufio"
+	"io"
+)
+
+// NamesRenderer writes bare package names, formulae before casks, for shell
+// pipelines. Casks are written by token, the name brew commands accept.
+type NamesRenderer struct {
+	// Null ends each name with a NUL byte instead of a newline, for xargs -0.
+	Null bool
+}
+
+// Render implements Renderer.
+func (r NamesRenderer) Render(view *View, writer io.Writer) error {
+	terminator := byte('\n')
+	if r.Null {
+		terminator = 0
+	}
+	w := bufio.NewWriter(writer)
+	for _, section := range view.Sections() {
+		for _, row := range section.Rows {
+			w.WriteString(row.Name)
+			w.WriteByte(terminator)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,260 @@
+package brewls
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Output format names accepted by NewRenderer.
+const (
+	FormatTable     = "table"
+	FormatJSON      = "json"
+	FormatCSV       = "csv"
+	FormatTSV       = "tsv"
+	FormatMarkdown  = "markdown"
+	FormatHTML      = "html"
+	FormatNames     = "names"
+	FormatBrewfile  = "brewfile"
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Renderer writes a View in a particular output format.
+type Renderer interface {
+	Render(view *View, writer io.Writer) error
+}
+
+// RenderOptions carries format-specific settings for NewRenderer.
+type RenderOptions struct {
+	JSONLines     bool   // json: one package object per line
+	ListSeparator string // csv, tsv: joins multi-value cells; DefaultListSeparator when empty
+	Color         bool   // table: color rows with ANSI escape sequences
+	Style         string // table: one of TableStyles; TableStyleDefault when empty
+	Width         int    // table: terminal width to fit list columns into; 0 means no limit
+	Null          bool   // names: end names with NUL instead of newline
+	Summary       bool   // table: end with a line of counts, see View.Summary
+	AllRequested  bool   // brewfile: keep every package installed on request, not only roots
+	Comments      bool   // brewfile: note what each package pulls in
+	// The remaining options describe the document for the SBOM formats.
+	Timestamp   time.Time // cyclonedx, spdx: creation time; the renderer's default when zero, see SourceDateEpoch
+	ToolVersion string    // cyclonedx, spdx: the brewls version that made it
+	Hostname    string    // cyclonedx, spdx: the machine it describes
+}
+
+// SourceDateEpochEnv fixes the creation time of SBOMs, as seconds since the
+// Unix epoch, for reproducible output (https://reproducible-builds.org/specs/source-date-epoch/).
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// SourceDateEpoch returns the time in SourceDateEpochEnv, or the zero time
+// when it is not set.
+func SourceDateEpoch() (time.Time, error) {
+	value := strings.TrimSpace(os.Getenv(SourceDateEpochEnv))
+	if value == "" {
+		return time.Time{}, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: expected seconds since the Unix epoch", SourceDateEpochEnv, value)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// RendererFactory builds a Renderer from the options given on the command line.
+type RendererFactory func(opts RenderOptions) Renderer
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]RendererFactory{
+		FormatTable: func(opts RenderOptions) Renderer {
+			return TableRenderer{Style: opts.Style, Color: opts.Color, Width: opts.Width, Summary: opts.Summary}
+		},
+		FormatJSON: func(opts RenderOptions) Renderer {
+			return JSONRenderer{Lines: opts.JSONLines}
+		},
+		FormatCSV: func(opts RenderOptions) Renderer {
+			return DelimitedRenderer{Comma: ',', ListSeparator: opts.ListSeparator}
+		},
+		FormatTSV: func(opts RenderOptions) Renderer {
+			return DelimitedRenderer{Comma: '\t', ListSeparator: opts.ListSeparator}
+		},
+		FormatMarkdown: func(RenderOptions) Renderer { return MarkdownRenderer{} },
+		FormatHTML:     func(RenderOptions) Renderer { return HTMLRenderer{} },
+		FormatBrewfile: func(opts RenderOptions) Renderer {
+			return BrewfileRenderer{AllRequested: opts.AllRequested, Comments: opts.Comments}
+		},
+		FormatCycloneDX: func(opts RenderOptions) Renderer {
+			return CycloneDXRenderer{Timestamp: opts.Timestamp, ToolVersion: opts.ToolVersion, Hostname: opts.Hostname}
+		},
+		FormatSPDX: func(opts RenderOptions) Renderer {
+			return SPDXRenderer{Timestamp: opts.Timestamp, ToolVersion: opts.ToolVersion, Hostname: opts.Hostname}
+		},
+		FormatNames: func(opts RenderOptions) Renderer {
+			return NamesRenderer{Null: opts.Null}
+		},
+	}
+)
+
+// RegisterRenderer makes a renderer available to NewRenderer under the given format name.
+// Registering an existing name replaces it, which lets embedders override built-in formats.
+func RegisterRenderer(format string, factory RendererFactory) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || factory == nil {
+		panic("brewls: RegisterRenderer requires a format name and factory")
+	}
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[format] = factory
+}
+
+// Formats returns the registered output format names, sorted.
+func Formats() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRenderer returns the Renderer registered for the named output format.
+func NewRenderer(format string, opts RenderOptions) (Renderer, error) {
+	if opts.ListSeparator == "" {
+		opts.ListSeparator = DefaultListSeparator
+	}
+
+	renderersMu.RLock()
+	factory, ok := renderers[strings.ToLower(strings.TrimSpace(format))]
+	renderersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (expected one of %s)", format, strings.Join(Formats(), ", "))
+	}
+	return factory(opts), nil
+}
+
+// TableRenderer draws formulae and casks as two go-pretty tables.
+type TableRenderer struct {
+	Style string // One of TableStyles; TableStyleDefault when empty
+	Color bool   // Color rows by status; ignored for TableStyleMarkdown
+	// Width is the terminal width. List columns such as Installed By are
+	// truncated with a "+N more" suffix to fit it; 0 means no limit.
+	Width int
+	// Summary adds a footer line counting the listed packages.
+	Summary bool
+}
+
+// Render implements Renderer.
+func (r TableRenderer) Render(view *View, writer io.Writer) error {
+	color := r.Color && r.Style != TableStyleMarkdown
+	for _, section := range view.Sections() {
+		title := section.Title
+		if color {
+			title = paint(colorHeader, title)
+		}
+		if _, err := fmt.Fprintf(writer, "\n--- %s ---\n", title); err != nil {
+			return err
+		}
+
+		style := goPrettyStyle(r.Style)
+		t := table.NewWriter()
+		t.SetStyle(style)
+		t.SetColumnConfigs(tableColumnConfigs(view.Columns))
+
+		header := view.Header()
+		rows := make([][]string, len(section.Rows))
+		for i, row := range section.Rows {
+			rows[i] = view.markedCells(row, DefaultListSeparator)
+		}
+		limits := listLimits(view.Columns, header, rows, style, r.Width)
+
+		if color {
+			header = colorHeaderCells(header)
+		}
+		t.AppendHeader(toTableRow(header))
+		for i, row := range section.Rows {
+			cells := rows[i]
+			for j, c := range view.Columns {
+				if items, ok := c.Value(row).([]string); ok && limits[j] > 0 && c.Format == nil {
+					cells[j] = truncateList(items, DefaultListSeparator, limits[j])
+				}
+			}
+			if color {
+				cells = colorCells(view.Columns, row, cells)
+			}
+			t.AppendRow(toTableRow(cells))
+		}
+		// Write the table ourselves: go-pretty drops the errors of its
+		// output mirror.
+		out := t.Render()
+		if r.Style == TableStyleMarkdown {
+			out = t.RenderMarkdown()
+		}
+		if out != "" {
+			if _, err := io.WriteString(writer, out+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	if r.Summary {
+		if _, err := fmt.Fprintf(writer, "\n%s\n", view.Summary()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tableColumnConfigs(columns []Column) []table.ColumnConfig {
+	configs := make([]table.ColumnConfig, len(columns))
+	for i, c := range columns {
+		configs[i] = table.ColumnConfig{Number: i + 1}
+		if c.Kind != KindList { // list columns are truncated by listLimits instead
+			configs[i].WidthMax = c.Width
+		}
+		if c.Align == AlignRight {
+			configs[i].Align = text.AlignRight
+		}
+	}
+	return configs
+}
+
+func toTableRow(cells []string) table.Row {
+	row := make(table.Row, len(cells))
+	for i, cell := range cells {
+		row[i] = cell
+	}
+	return row
+}
+
+// JSONRenderer writes the versioned JSON document, or JSON Lines when Lines is set.
+type JSONRenderer struct {
+	Lines bool
+}
+
+// Render implements Renderer.
+func (r JSONRenderer) Render(view *View, writer io.Writer) error {
+	if r.Lines {
+		return writeJSONLines(view, writer)
+	}
+	return writeJSON(view, writer)
+}
+
+// DelimitedRenderer writes CSV (Comma ',') or TSV (Comma '\t') with a header row.
+type DelimitedRenderer struct {
+	Comma         rune
+	ListSeparator string
+}
+
+// Render implements Renderer.
+func (r DelimitedRenderer) Render(view *View, writer io.Writer) error {
+	return writeDelimited(view, writer, r.Comma, r.ListSeparator)
+}
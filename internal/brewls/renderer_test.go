@@ -0,0 +1,109 @@
+package brewls_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range brewls.Formats() {
+		if _, err := brewls.NewRenderer(format, brewls.RenderOptions{}); err != nil {
+			t.Errorf("NewRenderer(%q) returned error: %v", format, err)
+		}
+	}
+
+	_, err := brewls.NewRenderer("xml", brewls.RenderOptions{})
+	if err == nil || !strings.Contains(err.Error(), `unknown output format "xml"`) {
+		t.Errorf("Expected unknown format error, got %v", err)
+	}
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv("BREWLS_FEATURES", "")
+
+	info := delimitedTestInfo()
+	info.Casks[0].Name = []string{"iTerm|2"}
+
+	var buf bytes.Buffer
+	if err := (brewls.MarkdownRenderer{}).Render(brewls.NewView(info, brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	expected := `## Homebrew Formulae
+
+| Name | Version | Installed By |
+| --- | --- | --- |
+| zlib | 1.3.1 | curl, libxml2 |
+| curl \* | 8.7.1 |  |
+| libxml2 | 2.12.6 |  |
+
+## Homebrew Casks
+
+| Name | Version | Installed By |
+| --- | --- | --- |
+| iTerm\|2 \* | 3.4.23 |  |
+`
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv("BREWLS_FEATURES", "")
+
+	info := delimitedTestInfo()
+	info.Casks[0].Name = []string{"<script>alert(1)</script>"}
+
+	var buf bytes.Buffer
+	if err := (brewls.HTMLRenderer{}).Render(brewls.NewView(info, brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<h2>Homebrew Formulae</h2>",
+		"<h2>Homebrew Casks</h2>",
+		"<th>Installed By</th>",
+		`<td data-value="curl">curl<span class="badge">root</span></td>`,
+		`<td data-value="zlib">zlib</td>`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected HTML to contain %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>alert(1)") {
+		t.Errorf("Expected cask name to be escaped")
+	}
+	if strings.Contains(out, "<link") || strings.Contains(out, "src=") {
+		t.Errorf("Expected a self-contained document without external resources")
+	}
+}
+
+func TestNamesRenderer(t *testing.T) {
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv("BREWLS_FEATURES", "")
+
+	view := brewls.NewView(delimitedTestInfo(), brewls.DefaultViewOptions())
+
+	var buf bytes.Buffer
+	if err := (brewls.NamesRenderer{}).Render(view, &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if want := "zlib\ncurl\nlibxml2\niterm2\n"; buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := (brewls.NamesRenderer{Null: true}).Render(view, &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if want := "zlib\x00curl\x00libxml2\x00iterm2\x00"; buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,128 @@
+package brewls
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Selector restricts a listing to packages named on the command line.
+// Patterns are exact names or shell globs such as "python@*"; Regexps are
+// matched against the name anywhere. A package matches when any pattern or
+// regular expression matches its formula name, full name or cask token.
+type Selector struct {
+	Patterns []string
+	Regexps  []*regexp.Regexp
+	WithDeps bool // also select the transitive dependencies of matched packages
+}
+
+// NewSelector validates glob patterns and compiles regular expressions.
+// It returns nil when there is nothing to select by, meaning "everything".
+func NewSelector(patterns, regexps []string, withDeps bool) (*Selector, error) {
+	if len(patterns) == 0 && len(regexps) == 0 {
+		return nil, nil
+	}
+
+	s := &Selector{Patterns: patterns, WithDeps: withDeps}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	for _, expr := range regexps {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+		s.Regexps = append(s.Regexps, re)
+	}
+	return s, nil
+}
+
+// Select returns the names of the packages chosen by s, keyed by formula name or
+// cask token. Exact names (patterns without glob characters) that match no
+// installed package are reported as an error.
+func (s *Selector) Select(info *BrewInfo) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	matchedPattern := make(map[string]bool)
+
+	consider := func(names ...string) bool {
+		hit := false
+		for _, name := range names {
+			if name == "" {
+				continue
+			}
+			for _, pattern := range s.Patterns {
+				if ok, _ := path.Match(pattern, name); ok {
+					matchedPattern[pattern] = true
+					hit = true
+				}
+			}
+			for _, re := range s.Regexps {
+				if re.MatchString(name) {
+					hit = true
+				}
+			}
+		}
+		return hit
+	}
+
+	var matchedFormulae []string
+	for _, f := range info.Formulae {
+		if consider(f.Name, f.FullName) {
+			selected[f.Name] = true
+			matchedFormulae = append(matchedFormulae, f.Name)
+		}
+	}
+	for _, c := range info.Casks {
+		if consider(c.Token) {
+			selected[c.Token] = true
+		}
+	}
+
+	var missing []string
+	for _, pattern := range s.Patterns {
+		if !matchedPattern[pattern] && !strings.ContainsAny(pattern, "*?[") {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no installed formula or cask named %s", strings.Join(missing, ", "))
+	}
+
+	if s.WithDeps {
+		for _, dep := range TransitiveClosure(InstalledDependencies(info), matchedFormulae) {
+			selected[dep] = true
+		}
+	}
+	return selected, nil
+}
+
+// Restrict removes every formula and cask that s does not select from info.
+// The reverse dependency graph should be built first so InstalledBy and IsRoot
+// still describe the whole installation. A nil Selector leaves info unchanged.
+func (s *Selector) Restrict(info *BrewInfo) error {
+	if s == nil {
+		return nil
+	}
+	selected, err := s.Select(info)
+	if err != nil {
+		return err
+	}
+
+	formulae := make([]Formula, 0, len(selected))
+	for _, f := range info.Formulae {
+		if selected[f.Name] {
+			formulae = append(formulae, f)
+		}
+	}
+	casks := make([]Cask, 0, len(selected))
+	for _, c := range info.Casks {
+		if selected[c.Token] {
+			casks = append(casks, c)
+		}
+	}
+	info.Formulae, info.Casks = formulae, casks
+	return nil
+}
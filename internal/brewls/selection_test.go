@@ -0,0 +1,120 @@
+package brewls_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func selectionTestInfo() *brewls.BrewInfo {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "python@3.12", Installed: []brewls.Installed{{Version: "3.12.3", InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "openssl@3"}, {FullName: "sqlite"}}}}},
+			{Name: "python@3.13", Installed: []brewls.Installed{{Version: "3.13.0", RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "openssl@3"}}}}},
+			{Name: "openssl@3", Installed: []brewls.Installed{{Version: "3.3.0", RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "ca-certificates"}}}}},
+			{Name: "ca-certificates", Installed: []brewls.Installed{{Version: "2024-03-11"}}},
+			{Name: "sqlite", Installed: []brewls.Installed{{Version: "3.45.3"}}, Dependencies: []string{"readline"}},
+			{Name: "terraform", FullName: "hashicorp/tap/terraform", Installed: []brewls.Installed{{Version: "1.8.2", InstalledOnRequest: true}}},
+		},
+		Casks: []brewls.Cask{
+			{Token: "python-launcher", Installed: "1.0"},
+			{Token: "firefox", Installed: "125.0"},
+		},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func TestInstalledDependencies(t *testing.T) {
+	deps := brewls.InstalledDependencies(selectionTestInfo())
+
+	if got, want := deps["python@3.12"], []string{"openssl@3", "sqlite"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected python@3.12 deps %v, got %v", want, got)
+	}
+	if got := deps["sqlite"]; len(got) != 0 {
+		t.Errorf("Expected uninstalled readline to be ignored, got %v", got)
+	}
+	if got, want := brewls.TransitiveClosure(deps, []string{"python@3.12"}), []string{"ca-certificates", "openssl@3", "sqlite"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected closure %v, got %v", want, got)
+	}
+}
+
+func TestTransitiveClosureToleratesCycles(t *testing.T) {
+	edges := map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}
+	if got, want := brewls.TransitiveClosure(edges, []string{"a"}), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSelectorRestrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		regexps  []string
+		withDeps bool
+		expected []string
+	}{
+		{name: "exact name", patterns: []string{"sqlite"}, expected: []string{"sqlite"}},
+		{name: "glob", patterns: []string{"python@*"}, expected: []string{"python@3.12", "python@3.13"}},
+		{name: "glob matching casks", patterns: []string{"python*"}, expected: []string{"python@3.12", "python@3.13", "python-launcher"}},
+		{name: "full name", patterns: []string{"hashicorp/tap/*"}, expected: []string{"terraform"}},
+		{name: "regex", regexps: []string{"^fire", "form$"}, expected: []string{"terraform", "firefox"}},
+		{name: "with deps", patterns: []string{"python@3.12"}, withDeps: true, expected: []string{"python@3.12", "openssl@3", "ca-certificates", "sqlite"}},
+		{name: "glob without matches", patterns: []string{"ruby@*"}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := brewls.NewSelector(tt.patterns, tt.regexps, tt.withDeps)
+			if err != nil {
+				t.Fatalf("NewSelector returned error: %v", err)
+			}
+			info := selectionTestInfo()
+			if err := selector.Restrict(info); err != nil {
+				t.Fatalf("Restrict returned error: %v", err)
+			}
+
+			names := []string{}
+			for _, f := range info.Formulae {
+				names = append(names, f.Name)
+			}
+			for _, c := range info.Casks {
+				names = append(names, c.Token)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestSelectorKeepsGraphOfWholeInstallation(t *testing.T) {
+	selector, _ := brewls.NewSelector([]string{"openssl@3"}, nil, false)
+	info := selectionTestInfo()
+	if err := selector.Restrict(info); err != nil {
+		t.Fatalf("Restrict returned error: %v", err)
+	}
+	if got, want := info.Formulae[0].InstalledBy, []string{"python@3.12", "python@3.13"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected InstalledBy %v, got %v", want, got)
+	}
+}
+
+func TestSelectorErrors(t *testing.T) {
+	if selector, err := brewls.NewSelector(nil, nil, true); selector != nil || err != nil {
+		t.Errorf("Expected no selector without patterns, got %v, %v", selector, err)
+	}
+	if _, err := brewls.NewSelector([]string{"python@["}, nil, false); err == nil || !strings.Contains(err.Error(), "invalid glob") {
+		t.Errorf("Expected invalid glob error, got %v", err)
+	}
+	if _, err := brewls.NewSelector(nil, []string{"("}, false); err == nil || !strings.Contains(err.Error(), "invalid regular expression") {
+		t.Errorf("Expected invalid regex error, got %v", err)
+	}
+
+	selector, _ := brewls.NewSelector([]string{"sqlite", "nodejs", "ruby@*"}, nil, false)
+	err := selector.Restrict(selectionTestInfo())
+	if err == nil || err.Error() != "no installed formula or cask named nodejs" {
+		t.Errorf("Expected missing package error, got %v", err)
+	}
+}
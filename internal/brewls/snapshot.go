@@ -0,0 +1,325 @@
+package brewls
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"brewls/pkg/version"
+)
+
+// SnapshotSchemaVersion is the version of the snapshot file format. Snapshots
+// with a newer version are rejected by LoadSnapshot.
+const SnapshotSchemaVersion = 1
+
+// Snapshot is the installed state at one point in time, as saved by
+// brewls snapshot save. It holds no paths of the machine it was taken on, so
+// snapshots can be compared anywhere.
+type Snapshot struct {
+	SchemaVersion int               `json:"schema_version"`
+	Name          string            `json:"name"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Hostname      string            `json:"hostname,omitempty"`
+	BrewVersion   string            `json:"brew_version,omitempty"` // e.g. "Homebrew 4.3.1"
+	Platform      string            `json:"platform,omitempty"`     // GOOS/GOARCH, e.g. "darwin/arm64"
+	Packages      []SnapshotPackage `json:"packages"`
+}
+
+// SnapshotPackage is an installed formula or cask with its place in the graph.
+type SnapshotPackage struct {
+	Type               string   `json:"type"` // PackageTypeFormula or PackageTypeCask
+	Name               string   `json:"name"`
+	Version            string   `json:"version"`
+	Tap                string   `json:"tap,omitempty"`
+	IsRoot             bool     `json:"is_root"`
+	InstalledOnRequest bool     `json:"installed_on_request"`
+	Dependencies       []string `json:"dependencies"` // installed packages it depends on directly
+	InstalledBy        []string `json:"installed_by"`
+}
+
+func (p SnapshotPackage) key() string { return p.Type + " " + p.Name }
+
+// NewSnapshot captures info, whose reverse dependency graph must have been
+// built. Callers fill in the name and metadata.
+func NewSnapshot(info *BrewInfo) *Snapshot {
+	deps := InstalledDependencies(info)
+	snapshot := &Snapshot{SchemaVersion: SnapshotSchemaVersion, Packages: []SnapshotPackage{}}
+	for _, f := range info.Formulae {
+		snapshot.Packages = append(snapshot.Packages, SnapshotPackage{
+			Type:               PackageTypeFormula,
+			Name:               f.Name,
+			Version:            f.InstalledVersion(),
+			Tap:                f.Tap,
+			IsRoot:             f.IsRoot,
+			InstalledOnRequest: f.InstalledOnRequest(),
+			Dependencies:       nonNil(deps[f.Name]),
+			InstalledBy:        nonNil(UniqueAndSortStrings(f.InstalledBy)),
+		})
+	}
+	for _, c := range info.Casks {
+		snapshot.Packages = append(snapshot.Packages, SnapshotPackage{
+			Type:               PackageTypeCask,
+			Name:               c.Token,
+			Version:            c.Installed,
+			Tap:                c.Tap,
+			IsRoot:             c.IsRoot,
+			InstalledOnRequest: true,
+			Dependencies:       []string{},
+			InstalledBy:        nonNil(UniqueAndSortStrings(c.InstalledBy)),
+		})
+	}
+	slices.SortFunc(snapshot.Packages, func(a, b SnapshotPackage) int { return strings.Compare(a.key(), b.key()) })
+	return snapshot
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+// DataDir returns the directory brewls keeps snapshots and history in:
+// brewls under $XDG_DATA_HOME or ~/.local/share.
+func DataDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate data dir: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "brewls"), nil
+}
+
+// SnapshotDir returns the directory snapshots are saved in, under DataDir.
+func SnapshotDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snapshots"), nil
+}
+
+// ValidSnapshotName reports whether name can be used as a snapshot file name.
+func ValidSnapshotName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid snapshot name %q (use letters, digits, dashes and dots, not starting with a dot)", name)
+	}
+	return nil
+}
+
+// ErrSnapshotExists is returned by SaveSnapshot when the name is taken.
+var ErrSnapshotExists = errors.New("snapshot already exists")
+
+// SaveSnapshot writes s to NAME.json in dir, creating dir as needed, and
+// returns the path. An existing snapshot is only replaced when overwrite is set.
+func SaveSnapshot(dir string, s *Snapshot, overwrite bool) (string, error) {
+	if err := ValidSnapshotName(s.Name); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, s.Name+".json")
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("%w: %s", ErrSnapshotExists, path)
+	} else if err != nil {
+		return "", err
+	}
+	if err := WriteSnapshot(file, s); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// SnapshotNames returns the names of the snapshots saved in dir, sorted. A
+// missing dir has none.
+func SnapshotNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// WriteSnapshot writes s as indented JSON.
+func WriteSnapshot(writer io.Writer, s *Snapshot) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// LoadSnapshot reads a snapshot file.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&s); err != nil {
+		return nil, fmt.Errorf("snapshot %s: invalid JSON: %w", path, err)
+	}
+	switch {
+	case s.SchemaVersion == 0:
+		return nil, fmt.Errorf("snapshot %s: not a brewls snapshot (no schema_version)", path)
+	case s.SchemaVersion > SnapshotSchemaVersion:
+		return nil, fmt.Errorf("snapshot %s: schema version %d is newer than this brewls supports (%d)", path, s.SchemaVersion, SnapshotSchemaVersion)
+	}
+	return &s, nil
+}
+
+// SnapshotDiff lists what changed from one snapshot to another.
+type SnapshotDiff struct {
+	Added          []SnapshotPackage `json:"added"`
+	Removed        []SnapshotPackage `json:"removed"`
+	VersionChanges []VersionChange   `json:"version_changes"`
+	RootChanges    []RootChange      `json:"root_changes"`
+	// AddedEdges and RemovedEdges are dependency edges between packages that
+	// are installed in both snapshots; edges of added or removed packages are
+	// implied by those.
+	AddedEdges   []DependencyEdge `json:"added_edges"`
+	RemovedEdges []DependencyEdge `json:"removed_edges"`
+}
+
+// VersionChange is a package whose installed version changed.
+type VersionChange struct {
+	Type string       `json:"type"`
+	Name string       `json:"name"`
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Bump version.Bump `json:"bump"`
+}
+
+// RootChange is a package that became a root or stopped being one.
+type RootChange struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	IsRoot bool   `json:"is_root"` // in the newer snapshot
+}
+
+// DependencyEdge is a dependency of From on To.
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Empty reports whether nothing changed.
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.VersionChanges) == 0 &&
+		len(d.RootChanges) == 0 && len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// DiffSnapshots compares an older snapshot a with a newer one b.
+func DiffSnapshots(a, b *Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		Added: []SnapshotPackage{}, Removed: []SnapshotPackage{}, VersionChanges: []VersionChange{},
+		RootChanges: []RootChange{}, AddedEdges: []DependencyEdge{}, RemovedEdges: []DependencyEdge{},
+	}
+	before := packagesByKey(a)
+	after := packagesByKey(b)
+
+	for _, p := range b.Packages {
+		old, ok := before[p.key()]
+		if !ok {
+			diff.Added = append(diff.Added, p)
+			continue
+		}
+		if old.Version != p.Version {
+			diff.VersionChanges = append(diff.VersionChanges, VersionChange{p.Type, p.Name, old.Version, p.Version, version.Classify(old.Version, p.Version)})
+		}
+		if old.IsRoot != p.IsRoot {
+			diff.RootChanges = append(diff.RootChanges, RootChange{p.Type, p.Name, p.IsRoot})
+		}
+		for _, dep := range p.Dependencies {
+			if !slices.Contains(old.Dependencies, dep) && installedIn(before, dep) {
+				diff.AddedEdges = append(diff.AddedEdges, DependencyEdge{p.Name, dep})
+			}
+		}
+		for _, dep := range old.Dependencies {
+			if !slices.Contains(p.Dependencies, dep) && installedIn(after, dep) {
+				diff.RemovedEdges = append(diff.RemovedEdges, DependencyEdge{p.Name, dep})
+			}
+		}
+	}
+	for _, p := range a.Packages {
+		if _, ok := after[p.key()]; !ok {
+			diff.Removed = append(diff.Removed, p)
+		}
+	}
+	return diff
+}
+
+func packagesByKey(s *Snapshot) map[string]SnapshotPackage {
+	packages := make(map[string]SnapshotPackage, len(s.Packages))
+	for _, p := range s.Packages {
+		packages[p.key()] = p
+	}
+	return packages
+}
+
+// installedIn reports whether a package of either type named name is in packages.
+func installedIn(packages map[string]SnapshotPackage, name string) bool {
+	_, formula := packages[PackageTypeFormula+" "+name]
+	_, cask := packages[PackageTypeCask+" "+name]
+	return formula || cask
+}
+
+// WriteSnapshotDiff prints diff one change per line: "+" and "-" for added and
+// removed packages and dependency edges, "~" for version changes with their
+// kind of bump, and "*" for packages that became or stopped being roots.
+func WriteSnapshotDiff(writer io.Writer, diff SnapshotDiff) error {
+	var b strings.Builder
+	for _, p := range diff.Added {
+		fmt.Fprintf(&b, "+ %s %s %s\n", p.Type, p.Name, p.Version)
+	}
+	for _, p := range diff.Removed {
+		fmt.Fprintf(&b, "- %s %s %s\n", p.Type, p.Name, p.Version)
+	}
+	for _, c := range diff.VersionChanges {
+		fmt.Fprintf(&b, "~ %s %s %s -> %s", c.Type, c.Name, c.From, c.To)
+		if c.Bump != version.None {
+			fmt.Fprintf(&b, " (%s)", c.Bump)
+		}
+		b.WriteByte('\n')
+	}
+	for _, c := range diff.RootChanges {
+		if c.IsRoot {
+			fmt.Fprintf(&b, "* %s %s is now a root\n", c.Type, c.Name)
+		} else {
+			fmt.Fprintf(&b, "* %s %s is no longer a root\n", c.Type, c.Name)
+		}
+	}
+	for _, e := range diff.AddedEdges {
+		fmt.Fprintf(&b, "+ %s → %s\n", e.From, e.To)
+	}
+	for _, e := range diff.RemovedEdges {
+		fmt.Fprintf(&b, "- %s → %s\n", e.From, e.To)
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
@@ -0,0 +1,125 @@
+package brewls_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	snapshot := brewls.NewSnapshot(sampleBrewInfo())
+	expected := []brewls.SnapshotPackage{
+		{Type: "cask", Name: "firefox", Version: "125.0", IsRoot: true, InstalledOnRequest: true, Dependencies: []string{}, InstalledBy: []string{}},
+		{Type: "formula", Name: "git", Version: "2.44.0", Tap: "homebrew/core", IsRoot: true, InstalledOnRequest: true, Dependencies: []string{"pcre2"}, InstalledBy: []string{}},
+		{Type: "formula", Name: "pcre2", Version: "10.42", Tap: "homebrew/core", Dependencies: []string{}, InstalledBy: []string{"git"}},
+	}
+	if snapshot.SchemaVersion != brewls.SnapshotSchemaVersion || !reflect.DeepEqual(snapshot.Packages, expected) {
+		t.Errorf("Expected packages %+v, got %+v", expected, snapshot.Packages)
+	}
+}
+
+func TestSaveAndLoadSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	snapshot := brewls.NewSnapshot(sampleBrewInfo())
+	snapshot.Name = "before-upgrade"
+	snapshot.CreatedAt = time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	snapshot.Hostname = "laptop"
+
+	path, err := brewls.SaveSnapshot(dir, snapshot, false)
+	if err != nil {
+		t.Fatalf("SaveSnapshot returned error: %v", err)
+	}
+	loaded, err := brewls.LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot returned error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("Expected %+v, got %+v", snapshot, loaded)
+	}
+
+	if _, err := brewls.SaveSnapshot(dir, snapshot, false); !errors.Is(err, brewls.ErrSnapshotExists) {
+		t.Errorf("Expected ErrSnapshotExists, got %v", err)
+	}
+	if _, err := brewls.SaveSnapshot(dir, snapshot, true); err != nil {
+		t.Errorf("Expected overwrite to succeed, got %v", err)
+	}
+	if names, err := brewls.SnapshotNames(dir); err != nil || !reflect.DeepEqual(names, []string{"before-upgrade"}) {
+		t.Errorf("Expected one snapshot name, got %v %v", names, err)
+	}
+
+	snapshot.Name = "../escape"
+	if _, err := brewls.SaveSnapshot(dir, snapshot, false); err == nil {
+		t.Error("Expected an invalid name to be rejected")
+	}
+
+	newer := filepath.Join(t.TempDir(), "newer.json")
+	os.WriteFile(newer, []byte(`{"schema_version": 99, "packages": []}`), 0o644)
+	if _, err := brewls.LoadSnapshot(newer); err == nil {
+		t.Error("Expected a newer schema version to be rejected")
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	before := brewls.NewSnapshot(statsTestInfo())
+
+	info := statsTestInfo()
+	info.Formulae[0].Installed[0].Version = "2.0"
+	info.Formulae[0].Installed[0].RuntimeDependencies = []brewls.RuntimeDependency{{FullName: "pcre2"}} // git no longer needs gettext
+	info.Formulae = slices.DeleteFunc(info.Formulae, func(f brewls.Formula) bool { return f.Name == "curl" || f.Name == "oldlib" })
+	info.Formulae = append(info.Formulae, brewls.Formula{Name: "htop", Installed: []brewls.Installed{{Version: "3.3.0", InstalledOnRequest: true}}})
+	brewls.BuildReverseDependencyGraph(info)
+	after := brewls.NewSnapshot(info)
+
+	diff := brewls.DiffSnapshots(before, after)
+	if len(diff.Added) != 1 || diff.Added[0].Name != "htop" {
+		t.Errorf("Expected htop added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 2 || diff.Removed[0].Name != "curl" || diff.Removed[1].Name != "oldlib" {
+		t.Errorf("Expected curl and oldlib removed, got %+v", diff.Removed)
+	}
+	if want := []brewls.VersionChange{{Type: "formula", Name: "git", From: "1.0", To: "2.0", Bump: "major"}}; !reflect.DeepEqual(diff.VersionChanges, want) {
+		t.Errorf("Expected %+v, got %+v", want, diff.VersionChanges)
+	}
+	if want := []brewls.DependencyEdge{{From: "git", To: "gettext"}}; !reflect.DeepEqual(diff.RemovedEdges, want) || len(diff.AddedEdges) != 0 {
+		t.Errorf("Expected removed edge %+v, got %+v / %+v", want, diff.RemovedEdges, diff.AddedEdges)
+	}
+
+	var buf bytes.Buffer
+	if err := brewls.WriteSnapshotDiff(&buf, diff); err != nil {
+		t.Fatalf("WriteSnapshotDiff returned error: %v", err)
+	}
+	want := `+ formula htop 3.3.0
+- formula curl 1.0
+- formula oldlib 1.0
+~ formula git 1.0 -> 2.0 (major)
+- git → gettext
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, buf.String())
+	}
+
+	if !brewls.DiffSnapshots(before, before).Empty() {
+		t.Error("Expected no changes between a snapshot and itself")
+	}
+}
+
+func TestDiffSnapshotsRootChanges(t *testing.T) {
+	before := brewls.NewSnapshot(statsTestInfo())
+	info := statsTestInfo()
+	info.Formulae[1].Installed[0].RuntimeDependencies = nil // wget no longer needs openssl@3 or gettext
+	info.Formulae[2].Installed[0].RuntimeDependencies = nil // nor does curl
+	info.Formulae[3].Installed[0].InstalledOnRequest = true // and openssl@3 was requested
+	brewls.BuildReverseDependencyGraph(info)
+
+	diff := brewls.DiffSnapshots(before, brewls.NewSnapshot(info))
+	if want := []brewls.RootChange{{Type: "formula", Name: "openssl@3", IsRoot: true}}; !reflect.DeepEqual(diff.RootChanges, want) {
+		t.Errorf("Expected %+v, got %+v", want, diff.RootChanges)
+	}
+}
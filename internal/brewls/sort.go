@@ -0,0 +1,113 @@
+package brewls
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortKey orders rows by one column.
+type SortKey struct {
+	Column     Column
+	Descending bool
+}
+
+func (k SortKey) String() string {
+	if k.Descending {
+		return "-" + k.Column.Key
+	}
+	return k.Column.Key
+}
+
+// ParseSortKeys parses a comma-separated sort specification such as
+// "-installed_by_count,name". A leading "-" sorts that key in descending
+// order and an optional leading "+" in ascending order. Any registered column may be used.
+func ParseSortKeys(raw string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key := SortKey{}
+		switch item[0] {
+		case '-':
+			key.Descending = true
+			item = item[1:]
+		case '+':
+			item = item[1:]
+		}
+
+		c, ok := LookupColumn(item)
+		if !ok {
+			return nil, fmt.Errorf("unknown sort key %q (available: %s)", item, strings.Join(ColumnKeys(), ", "))
+		}
+		key.Column = c
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SortRows orders rows by keys. The sort is stable, and rows that compare equal
+// on every key fall back to name then type, so the result never depends on input order.
+func SortRows(rows []Row, keys []SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, key := range keys {
+			cmp := key.Column.compare(rows[i], rows[j])
+			if key.Descending {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		if rows[i].Name != rows[j].Name {
+			return rows[i].Name < rows[j].Name
+		}
+		return rows[i].Type < rows[j].Type
+	})
+}
+
+// compareValues returns -1, 0 or 1 comparing two column values of the same kind.
+// Booleans order false before true; lists compare element by element.
+func compareValues(a, b any) int {
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case int64:
+		y := b.(int64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	case []string:
+		y := b.([]string)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if cmp := strings.Compare(x[i], y[i]); cmp != 0 {
+				return cmp
+			}
+		}
+		return compareValues(int64(len(x)), int64(len(y)))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
@@ -0,0 +1,81 @@
+package brewls_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := brewls.ParseSortKeys(" -count, +name,size ")
+	if err != nil {
+		t.Fatalf("ParseSortKeys returned error: %v", err)
+	}
+	var got []string
+	for _, key := range keys {
+		got = append(got, key.String())
+	}
+	if want := []string{"-installed_by_count", "name", "size"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := brewls.ParseSortKeys("-popularity"); err == nil || !strings.Contains(err.Error(), `unknown sort key "popularity"`) {
+		t.Errorf("Expected unknown sort key error, got %v", err)
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	rows := []brewls.Row{
+		{Type: "formula", Name: "b", Version: "2.10", InstalledBy: []string{"x"}, SizeBytes: 10},
+		{Type: "formula", Name: "c", Version: "2.9", InstalledBy: []string{"x", "y"}, SizeBytes: 30},
+		{Type: "formula", Name: "a", Version: "10.0", InstalledBy: []string{"x"}, SizeBytes: 20, IsRoot: true},
+		{Type: "formula", Name: "d", Version: "2.9_1", SizeBytes: 20},
+	}
+
+	tests := []struct {
+		spec     string
+		expected []string
+	}{
+		{spec: "name", expected: []string{"a", "b", "c", "d"}},
+		{spec: "-name", expected: []string{"d", "c", "b", "a"}},
+		{spec: "-installed_by_count,name", expected: []string{"c", "a", "b", "d"}},
+		{spec: "-installed_by_count,-name", expected: []string{"c", "b", "a", "d"}},
+		{spec: "size", expected: []string{"b", "a", "d", "c"}},
+		{spec: "-root", expected: []string{"a", "b", "c", "d"}},
+		{spec: "installed_by", expected: []string{"d", "a", "b", "c"}},
+		{spec: "version", expected: []string{"c", "d", "b", "a"}},
+		{spec: "-version", expected: []string{"a", "b", "d", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			sorted := append([]brewls.Row(nil), rows...)
+			brewls.SortRows(sorted, mustParseSortKeys(t, tt.spec))
+
+			var names []string
+			for _, row := range sorted {
+				names = append(names, row.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestNewViewSortsFormulaeAndCasksAlike(t *testing.T) {
+	info := delimitedTestInfo()
+	info.Casks = append(info.Casks, brewls.Cask{Token: "alacritty", Installed: "0.13.2", InstalledBy: []string{"x"}})
+
+	view := brewls.NewView(info, brewls.ViewOptions{Sort: mustParseSortKeys(t, "-count,name")})
+
+	var names []string
+	for _, row := range view.Rows() {
+		names = append(names, row.Name)
+	}
+	if want := []string{"zlib", "curl", "libxml2", "alacritty", "iterm2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
@@ -0,0 +1,204 @@
+package brewls
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SPDXVersion is the SPDX specification version of the documents written by
+// SPDXRenderer.
+const SPDXVersion = "SPDX-2.3"
+
+// SPDXRenderer writes the view as an SPDX JSON software bill of materials.
+// The namespace is a hash of the document's content, so every distinct
+// document gets its own and exporting an unchanged installation twice gives
+// the same one. Without a Timestamp the creation time is the latest install
+// time of the packages, so such exports are identical byte for byte.
+type SPDXRenderer struct {
+	Timestamp   time.Time // document creation time; see above when zero
+	ToolVersion string    // brewls version recorded as the creator
+	Hostname    string    // the machine described, when known
+}
+
+// SPDXDocument is the subset of an SPDX 2.3 document that brewls writes.
+type SPDXDocument struct {
+	SPDXVersion       string                     `json:"spdxVersion"`
+	DataLicense       string                     `json:"dataLicense"`
+	SPDXID            string                     `json:"SPDXID"`
+	Name              string                     `json:"name"`
+	DocumentNamespace string                     `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo           `json:"creationInfo"`
+	Packages          []SPDXPackage              `json:"packages"`
+	Relationships     []SPDXRelationship         `json:"relationships"`
+	ExtractedLicenses []SPDXExtractedLicenseInfo `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+// SPDXCreationInfo says when and by what a document was made.
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is a formula or cask in an SPDX document.
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Homepage         string            `json:"homepage,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Summary          string            `json:"summary,omitempty"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs"`
+}
+
+// SPDXExternalRef identifies a package outside the document, here by purl.
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// SPDXRelationship relates two elements, e.g. a package DEPENDS_ON another.
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDXExtractedLicenseInfo defines a LicenseRef- for a license SPDX has no
+// identifier for, such as "Public Domain".
+type SPDXExtractedLicenseInfo struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+// spdxNoAssertion is SPDX's value for information that was not determined.
+const spdxNoAssertion = "NOASSERTION"
+
+var spdxIDInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Render implements Renderer.
+func (r SPDXRenderer) Render(view *View, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.Document(view))
+}
+
+// Document builds the SPDX document for view.
+func (r SPDXRenderer) Document(view *View) SPDXDocument {
+	name := "brewls"
+	if r.Hostname != "" {
+		name += "-" + r.Hostname
+	}
+	doc := SPDXDocument{
+		SPDXVersion: SPDXVersion,
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        name,
+		CreationInfo: SPDXCreationInfo{
+			Creators: []string{"Tool: brewls-" + r.ToolVersion},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+	if r.ToolVersion == "" {
+		doc.CreationInfo.Creators = []string{"Tool: brewls"}
+	}
+
+	rows := view.Rows()
+	ids := make(map[string]string, len(rows)) // by name; formulae win over casks of the same name
+	used := make(map[string]bool, len(rows))
+	extracted := make(map[string]bool)
+
+	for _, row := range rows {
+		id := "SPDXRef-" + row.Type + "-" + strings.Trim(spdxIDInvalid.ReplaceAllString(row.Name, "-"), "-")
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("SPDXRef-%s-%s-%d", row.Type, strings.Trim(spdxIDInvalid.ReplaceAllString(row.Name, "-"), "-"), n)
+		}
+		used[id] = true
+		if _, ok := ids[row.Name]; !ok || row.Type == PackageTypeFormula {
+			ids[row.Name] = id
+		}
+
+		purl := PackageURL(row.Type, row.Name, row.Version, row.Tap)
+		pkg := SPDXPackage{
+			SPDXID:           id,
+			Name:             row.Name,
+			VersionInfo:      row.Version,
+			DownloadLocation: spdxNoAssertion,
+			Homepage:         row.Homepage,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			Summary:          row.Desc,
+			PrimaryPurpose:   "LIBRARY",
+			ExternalRefs:     []SPDXExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}},
+		}
+		if row.Type == PackageTypeCask || (row.Formula != nil && row.Formula.InstalledOnRequest()) {
+			pkg.PrimaryPurpose = "APPLICATION"
+		}
+		if row.Formula != nil && row.Formula.License != "" {
+			license := row.Formula.License
+			if expression, ok := SPDXExpression(license); ok {
+				pkg.LicenseDeclared = expression
+			} else {
+				pkg.LicenseDeclared = "LicenseRef-" + strings.Trim(spdxIDInvalid.ReplaceAllString(license, "-"), "-")
+				if !extracted[pkg.LicenseDeclared] {
+					extracted[pkg.LicenseDeclared] = true
+					doc.ExtractedLicenses = append(doc.ExtractedLicenses, SPDXExtractedLicenseInfo{
+						LicenseID: pkg.LicenseDeclared, ExtractedText: license, Name: license,
+					})
+				}
+			}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+
+	// The document describes the root packages, or every package when the
+	// selection has none.
+	hasRoot := false
+	for _, row := range rows {
+		hasRoot = hasRoot || row.IsRoot
+	}
+	for i, row := range rows {
+		if row.IsRoot || !hasRoot {
+			doc.Relationships = append(doc.Relationships, SPDXRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", doc.Packages[i].SPDXID})
+		}
+	}
+	// Each package that installed another depends on it.
+	for i, row := range rows {
+		for _, dependent := range row.InstalledBy {
+			if id, ok := ids[dependent]; ok {
+				doc.Relationships = append(doc.Relationships, SPDXRelationship{id, "DEPENDS_ON", doc.Packages[i].SPDXID})
+			}
+		}
+	}
+
+	// SPDX requires a namespace unique to each distinct document, so hash
+	// everything but the namespace itself and the creation time.
+	content, _ := json.Marshal(doc) // plain structs of strings cannot fail
+	sum := sha256.Sum256(content)
+	doc.DocumentNamespace = fmt.Sprintf("https://spdx.org/spdxdocs/%s-%x", name, sum[:16])
+
+	created := r.Timestamp
+	if created.IsZero() {
+		created = time.Unix(0, 0)
+		for _, row := range rows {
+			if row.InstalledAt.After(created) {
+				created = row.InstalledAt
+			}
+		}
+	}
+	doc.CreationInfo.Created = created.UTC().Format(time.RFC3339)
+	return doc
+}
@@ -0,0 +1,119 @@
+package brewls_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func renderSPDX(t *testing.T, info *brewls.BrewInfo) []byte {
+	t.Helper()
+	view := brewls.NewView(info, brewls.ViewOptions{})
+	view.Installation = info
+	var buf bytes.Buffer
+	if err := (brewls.SPDXRenderer{Timestamp: time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC), ToolVersion: "v1.2.3", Hostname: "laptop"}).Render(view, &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSPDXRenderer(t *testing.T) {
+	info := sampleBrewInfo()
+	info.Formulae[0].License = "GPL-2.0-only"
+	info.Formulae[1].License = "Public Domain"
+	data := renderSPDX(t, info)
+
+	var doc brewls.SPDXDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, data)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DataLicense != "CC0-1.0" || doc.SPDXID != "SPDXRef-DOCUMENT" || doc.Name != "brewls-laptop" {
+		t.Errorf("Unexpected document header %+v", doc)
+	}
+	if doc.CreationInfo.Created != "2026-10-01T09:30:00Z" || !slices.Equal(doc.CreationInfo.Creators, []string{"Tool: brewls-v1.2.3"}) {
+		t.Errorf("Unexpected creation info %+v", doc.CreationInfo)
+	}
+	if !regexp.MustCompile(`^https://spdx\.org/spdxdocs/brewls-laptop-[0-9a-f]{32}$`).MatchString(doc.DocumentNamespace) {
+		t.Errorf("Unexpected namespace %q", doc.DocumentNamespace)
+	}
+
+	// Every element is a package with a valid, unique SPDXID and the fields
+	// SPDX 2.3 requires; relationships only refer to those.
+	ids := map[string]bool{"SPDXRef-DOCUMENT": true}
+	for _, p := range doc.Packages {
+		if !regexp.MustCompile(`^SPDXRef-[A-Za-z0-9.-]+$`).MatchString(p.SPDXID) || ids[p.SPDXID] {
+			t.Errorf("Invalid or duplicate SPDXID %q", p.SPDXID)
+		}
+		ids[p.SPDXID] = true
+		if p.Name == "" || p.DownloadLocation == "" || p.CopyrightText == "" || p.LicenseConcluded == "" || len(p.ExternalRefs) != 1 {
+			t.Errorf("Package is missing required fields: %+v", p)
+		}
+	}
+	for _, r := range doc.Relationships {
+		if !ids[r.SPDXElementID] || !ids[r.RelatedSPDXElement] {
+			t.Errorf("Relationship refers to an unknown element: %+v", r)
+		}
+	}
+
+	packages := make(map[string]brewls.SPDXPackage)
+	for _, p := range doc.Packages {
+		packages[p.Name] = p
+	}
+	if git := packages["git"]; git.SPDXID != "SPDXRef-formula-git" || git.LicenseDeclared != "GPL-2.0-only" || git.PrimaryPurpose != "APPLICATION" ||
+		git.ExternalRefs[0].ReferenceLocator != "pkg:brew/git@2.44.0" {
+		t.Errorf("Unexpected git package %+v", git)
+	}
+	if pcre2 := packages["pcre2"]; pcre2.LicenseDeclared != "LicenseRef-Public-Domain" || pcre2.PrimaryPurpose != "LIBRARY" {
+		t.Errorf("Unexpected pcre2 package %+v", pcre2)
+	}
+	if packages["firefox"].LicenseDeclared != "NOASSERTION" {
+		t.Errorf("Expected no license assertion for the cask, got %q", packages["firefox"].LicenseDeclared)
+	}
+	if len(doc.ExtractedLicenses) != 1 || doc.ExtractedLicenses[0].LicenseID != "LicenseRef-Public-Domain" {
+		t.Errorf("Expected the Public Domain license to be defined, got %+v", doc.ExtractedLicenses)
+	}
+
+	dependsOn := brewls.SPDXRelationship{SPDXElementID: "SPDXRef-formula-git", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-formula-pcre2"}
+	describes := brewls.SPDXRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-cask-firefox"}
+	if !slices.Contains(doc.Relationships, dependsOn) || !slices.Contains(doc.Relationships, describes) || len(doc.Relationships) != 3 {
+		t.Errorf("Unexpected relationships %+v", doc.Relationships)
+	}
+}
+
+func TestSPDXRendererIsDeterministic(t *testing.T) {
+	first := renderSPDX(t, sampleBrewInfo())
+	if second := renderSPDX(t, sampleBrewInfo()); !bytes.Equal(first, second) {
+		t.Errorf("Expected identical exports of the same state:\n%s\n%s", first, second)
+	}
+
+	// Without a Timestamp the latest install time is the creation time.
+	info := sampleBrewInfo()
+	info.Formulae[1].Installed[0].Time = time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC).Unix()
+	if created := (brewls.SPDXRenderer{}).Document(brewls.NewView(info, brewls.ViewOptions{})).CreationInfo.Created; created != "2026-09-01T08:00:00Z" {
+		t.Errorf("Expected the latest install time, got %q", created)
+	}
+
+	// Any change to the content, not only to versions, gets a new namespace.
+	var before brewls.SPDXDocument
+	json.Unmarshal(first, &before)
+	for name, change := range map[string]func(*brewls.BrewInfo){
+		"version":  func(info *brewls.BrewInfo) { info.Formulae[1].Installed[0].Version = "10.43" },
+		"license":  func(info *brewls.BrewInfo) { info.Formulae[1].License = "BSD-3-Clause" },
+		"homepage": func(info *brewls.BrewInfo) { info.Formulae[1].Homepage = "https://pcre2project.github.io" },
+		"desc":     func(info *brewls.BrewInfo) { info.Formulae[1].Desc = "Perl compatible regular expressions" },
+		"purpose":  func(info *brewls.BrewInfo) { info.Formulae[1].Installed[0].InstalledOnRequest = true },
+	} {
+		info := sampleBrewInfo()
+		change(info)
+		var after brewls.SPDXDocument
+		json.Unmarshal(renderSPDX(t, info), &after)
+		if before.DocumentNamespace == after.DocumentNamespace {
+			t.Errorf("Expected a different namespace after changing the %s", name)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package brewls
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Stats aggregates an installation whose reverse dependency graph has been built.
+type Stats struct {
+	Formulae int `json:"formulae"`
+	Casks    int `json:"casks"`
+	// Every installed package is a root, a dependency of another installed
+	// package, or an orphan: a formula installed as a dependency that nothing
+	// needs any more. Formulae without an installed version are none of them.
+	Roots        int `json:"roots"`
+	Dependencies int `json:"dependencies"`
+	Orphans      int `json:"orphans"`
+	Outdated     int `json:"outdated"`
+	// Depth is the longest chain of installed dependencies below a root
+	// formula, as drawn by brewls tree, averaged and maximized over the root
+	// formulae. Casks have no dependency graph and are left out.
+	AverageDepth float64 `json:"average_depth"`
+	MaxDepth     int     `json:"max_depth"`
+	// MostDependedOn lists the packages with the most transitive dependents,
+	// most first; see ComputeStats.
+	MostDependedOn []DependentCount `json:"most_depended_on"`
+}
+
+// DependentCount is the number of installed packages that depend on a package,
+// directly or through others.
+type DependentCount struct {
+	Name       string `json:"name"`
+	Dependents int    `json:"dependents"`
+}
+
+// ComputeStats aggregates info, listing up to top packages in MostDependedOn.
+// BuildReverseDependencyGraph must have been called first.
+func ComputeStats(info *BrewInfo, top int) Stats {
+	top = max(top, 0)
+	var rows []Row
+	for i := range info.Formulae {
+		rows = append(rows, FormulaRow(&info.Formulae[i]))
+	}
+	for i := range info.Casks {
+		rows = append(rows, CaskRow(&info.Casks[i]))
+	}
+	stats := tally(rows)
+
+	depths := dependencyDepths(InstalledDependencies(info))
+	total, roots := 0, 0
+	for _, f := range info.Formulae {
+		if f.IsRoot {
+			total += depths[f.Name]
+			roots++
+			stats.MaxDepth = max(stats.MaxDepth, depths[f.Name])
+		}
+	}
+	if roots > 0 {
+		stats.AverageDepth = float64(total) / float64(roots)
+	}
+
+	installedBy := make(map[string][]string, len(rows))
+	for _, row := range rows {
+		installedBy[row.Name] = row.InstalledBy
+	}
+	counts := make([]DependentCount, 0, len(rows))
+	for _, row := range rows {
+		if n := len(TransitiveClosure(installedBy, []string{row.Name})); n > 0 {
+			counts = append(counts, DependentCount{Name: row.Name, Dependents: n})
+		}
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Dependents != counts[j].Dependents {
+			return counts[i].Dependents > counts[j].Dependents
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	stats.MostDependedOn = counts[:min(top, len(counts))]
+	return stats
+}
+
+// dependencyDepths returns the length of the longest chain of dependencies
+// below each package in deps. Packages that depend on each other in a cycle
+// are first collapsed into one group (a strongly connected component), which
+// counts as a chain through its members, so every package gets the same depth
+// whichever walk reaches it first.
+func dependencyDepths(deps map[string][]string) map[string]int {
+	// Tarjan's algorithm numbers the groups in reverse topological order, so
+	// a group's dependencies always have lower numbers.
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	group := make(map[string]int)
+	var stack []string
+	var members [][]string
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = len(index)
+		lowlink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, dep := range deps[name] {
+			if _, seen := index[dep]; !seen {
+				visit(dep)
+				lowlink[name] = min(lowlink[name], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[name] = min(lowlink[name], index[dep])
+			}
+		}
+		if lowlink[name] == index[name] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				group[top] = len(members)
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			members = append(members, component)
+		}
+	}
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+
+	groupDepths := make([]int, len(members))
+	for g, component := range members {
+		chain := len(component) - 1 // through the group's own members
+		depth := chain
+		for _, name := range component {
+			for _, dep := range deps[name] {
+				if group[dep] != g {
+					depth = max(depth, chain+1+groupDepths[group[dep]])
+				}
+			}
+		}
+		groupDepths[g] = depth
+	}
+	depths := make(map[string]int, len(group))
+	for name, g := range group {
+		depths[name] = groupDepths[g]
+	}
+	return depths
+}
+
+// tally counts rows by type and status.
+func tally(rows []Row) Stats {
+	var stats Stats
+	for _, row := range rows {
+		if row.Type == PackageTypeCask {
+			stats.Casks++
+		} else {
+			stats.Formulae++
+		}
+		switch {
+		case row.IsRoot:
+			stats.Roots++
+		case len(row.InstalledBy) > 0:
+			stats.Dependencies++
+		case row.Orphan:
+			stats.Orphans++
+		}
+		if row.Outdated {
+			stats.Outdated++
+		}
+	}
+	return stats
+}
+
+// Summary returns a one-line count of the rows in the view, such as
+// "12 formulae, 3 casks: 8 roots, 6 dependencies, 1 orphan, 2 outdated".
+func (v *View) Summary() string {
+	stats := tally(v.Rows())
+	return fmt.Sprintf("%s, %s: %s, %s, %s, %d outdated",
+		plural(stats.Formulae, "formula", "formulae"), plural(stats.Casks, "cask", "casks"),
+		plural(stats.Roots, "root", "roots"), plural(stats.Dependencies, "dependency", "dependencies"),
+		plural(stats.Orphans, "orphan", "orphans"), stats.Outdated)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
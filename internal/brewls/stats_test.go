@@ -0,0 +1,111 @@
+package brewls_test
+
+import (
+	"reflect"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func statsTestInfo() *brewls.BrewInfo {
+	formula := func(name string, onRequest, outdated bool, deps ...string) brewls.Formula {
+		var runtime []brewls.RuntimeDependency
+		for _, dep := range deps {
+			runtime = append(runtime, brewls.RuntimeDependency{FullName: dep})
+		}
+		return brewls.Formula{Name: name, Outdated: outdated, Installed: []brewls.Installed{{Version: "1.0", InstalledOnRequest: onRequest, RuntimeDependencies: runtime}}}
+	}
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			formula("git", true, false, "pcre2", "gettext"),
+			formula("wget", true, true, "openssl@3", "gettext"),
+			formula("curl", true, false, "openssl@3"),
+			formula("openssl@3", false, true, "ca-certificates"),
+			formula("ca-certificates", false, false),
+			formula("pcre2", false, false),
+			formula("gettext", false, false),
+			formula("oldlib", false, false),
+		},
+		Casks: []brewls.Cask{{Token: "firefox", Installed: "125.0"}},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func TestComputeStats(t *testing.T) {
+	stats := brewls.ComputeStats(statsTestInfo(), 3)
+
+	expected := brewls.Stats{
+		Formulae:     8,
+		Casks:        1,
+		Roots:        4,
+		Dependencies: 4,
+		Orphans:      1,
+		Outdated:     2,
+		AverageDepth: 5.0 / 3, // git 1, wget 2, curl 2; casks have no dependency depth
+		MaxDepth:     2,
+		MostDependedOn: []brewls.DependentCount{
+			{Name: "ca-certificates", Dependents: 3},
+			{Name: "gettext", Dependents: 2},
+			{Name: "openssl@3", Dependents: 2},
+		},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+
+	if got := brewls.ComputeStats(statsTestInfo(), 100).MostDependedOn; len(got) != 4 {
+		t.Errorf("Expected every package with dependents, got %+v", got)
+	}
+	if got := brewls.ComputeStats(statsTestInfo(), -1).MostDependedOn; len(got) != 0 {
+		t.Errorf("Expected a negative top to list nothing, got %+v", got)
+	}
+
+	// A formula brew knows but has no installed version of is no orphan.
+	info := statsTestInfo()
+	info.Formulae = append(info.Formulae, brewls.Formula{Name: "ghost"})
+	brewls.BuildReverseDependencyGraph(info)
+	if stats := brewls.ComputeStats(info, 3); stats.Formulae != 9 || stats.Orphans != 1 || stats.Roots != 4 || stats.Dependencies != 4 {
+		t.Errorf("Expected ghost to count as a formula only, got %+v", stats)
+	}
+}
+
+func TestComputeStatsCycle(t *testing.T) {
+	info := &brewls.BrewInfo{Formulae: []brewls.Formula{
+		{Name: "app", Installed: []brewls.Installed{{InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "a"}}}}},
+		{Name: "a", Installed: []brewls.Installed{{RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "b"}}}}},
+		{Name: "b", Installed: []brewls.Installed{{RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "a"}}}}},
+	}}
+	brewls.BuildReverseDependencyGraph(info)
+	if stats := brewls.ComputeStats(info, 10); stats.MaxDepth != 2 {
+		t.Errorf("Expected the cycle to be cut at depth 2, got %+v", stats)
+	}
+
+	// Depths do not depend on the order packages are walked in: the cycle
+	// b <-> c counts as a chain of both, with d below it.
+	formula := func(name string, onRequest bool, deps ...string) brewls.Formula {
+		var runtime []brewls.RuntimeDependency
+		for _, dep := range deps {
+			runtime = append(runtime, brewls.RuntimeDependency{FullName: dep})
+		}
+		return brewls.Formula{Name: name, Installed: []brewls.Installed{{Version: "1.0", InstalledOnRequest: onRequest, RuntimeDependencies: runtime}}}
+	}
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {1, 0, 3, 2}} {
+		all := []brewls.Formula{formula("a", true, "b"), formula("b", true, "c"), formula("c", false, "b", "d"), formula("d", false)}
+		info := &brewls.BrewInfo{}
+		for _, i := range order {
+			info.Formulae = append(info.Formulae, all[i])
+		}
+		brewls.BuildReverseDependencyGraph(info)
+		if stats := brewls.ComputeStats(info, 10); stats.MaxDepth != 3 || stats.AverageDepth != 3 {
+			t.Errorf("Order %v: expected depth 3 for every root, got %+v", order, stats)
+		}
+	}
+}
+
+func TestViewSummary(t *testing.T) {
+	view := brewls.NewView(statsTestInfo(), brewls.ViewOptions{})
+	if want := "8 formulae, 1 cask: 4 roots, 4 dependencies, 1 orphan, 2 outdated"; view.Summary() != want {
+		t.Errorf("Expected %q, got %q", want, view.Summary())
+	}
+}
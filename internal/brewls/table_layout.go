@@ -0,0 +1,95 @@
+package brewls
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// minListWidth is the narrowest a list column is squeezed to when fitting a
+// table to the terminal; below it, the table is allowed to overflow.
+const minListWidth = 16
+
+// listLimits returns, for each column, the width a list column's cell is
+// truncated to (see truncateList), or 0 to leave the cell alone. List columns
+// with a Width use it; others get an equal share of what remains of width
+// after the other columns, borders and padding. width <= 0 means no limit.
+func listLimits(columns []Column, header []string, rows [][]string, style table.Style, width int) []int {
+	limits := make([]int, len(columns))
+	var flexible []int
+	for i, c := range columns {
+		if c.Kind != KindList {
+			continue
+		}
+		if c.Width > 0 {
+			limits[i] = c.Width
+		} else if width > 0 {
+			flexible = append(flexible, i)
+		}
+	}
+	if len(flexible) == 0 {
+		return limits
+	}
+
+	used := tableOverhead(style, len(columns))
+	for i, c := range columns {
+		if limits[i] > 0 {
+			used += limits[i]
+			continue
+		}
+		if c.Kind == KindList && c.Width == 0 {
+			continue
+		}
+		natural := text.StringWidthWithoutEscSequences(header[i])
+		for _, cells := range rows {
+			natural = max(natural, text.StringWidthWithoutEscSequences(cells[i]))
+		}
+		if c.Width > 0 {
+			natural = min(natural, c.Width)
+		}
+		used += natural
+	}
+
+	share := max((width-used)/len(flexible), minListWidth)
+	for _, i := range flexible {
+		limits[i] = max(share, text.StringWidthWithoutEscSequences(header[i]))
+	}
+	return limits
+}
+
+// tableOverhead is the number of terminal columns a go-pretty style spends on
+// borders, separators and padding for a table with n columns.
+func tableOverhead(style table.Style, n int) int {
+	padding := text.StringWidth(style.Box.PaddingLeft) + text.StringWidth(style.Box.PaddingRight)
+	overhead := n * padding
+	if style.Options.SeparateColumns && n > 1 {
+		overhead += (n - 1) * text.StringWidth(style.Box.MiddleVertical)
+	}
+	if style.Options.DrawBorder {
+		overhead += text.StringWidth(style.Box.Left) + text.StringWidth(style.Box.Right)
+	}
+	return overhead
+}
+
+// truncateList joins items with sep when the result fits in limit columns.
+// Otherwise it keeps as many leading items as fit and appends "+N more" for
+// the rest, e.g. "curl, git +12 more". At least one item is always kept; a
+// single item that does not fit is cut with an ellipsis instead.
+func truncateList(items []string, sep string, limit int) string {
+	full := strings.Join(items, sep)
+	if limit <= 0 || text.StringWidthWithoutEscSequences(full) <= limit {
+		return full
+	}
+	if len(items) == 1 {
+		return text.Snip(full, limit, "…")
+	}
+	for n := len(items) - 1; n > 1; n-- {
+		s := fmt.Sprintf("%s +%d more", strings.Join(items[:n], sep), len(items)-n)
+		if text.StringWidthWithoutEscSequences(s) <= limit {
+			return s
+		}
+	}
+	return fmt.Sprintf("%s +%d more", items[0], len(items)-1)
+}
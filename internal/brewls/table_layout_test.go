@@ -0,0 +1,102 @@
+package brewls_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// manyDependentsInfo has openssl@3 installed by 30 root formulae.
+func manyDependentsInfo() *brewls.BrewInfo {
+	info := &brewls.BrewInfo{Formulae: []brewls.Formula{
+		{Name: "openssl@3", Installed: []brewls.Installed{{Version: "3.3.0"}}},
+	}}
+	for i := range 30 {
+		info.Formulae = append(info.Formulae, brewls.Formula{
+			Name: fmt.Sprintf("tool%02d", i),
+			Installed: []brewls.Installed{{
+				Version:             "1.0",
+				InstalledOnRequest:  true,
+				RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "openssl@3"}},
+			}},
+		})
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+func renderTable(t *testing.T, renderer brewls.TableRenderer, options brewls.ViewOptions) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := renderer.Render(brewls.NewView(manyDependentsInfo(), options), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	return buf.String()
+}
+
+func maxLineWidth(s string) int {
+	width := 0
+	for _, line := range strings.Split(s, "\n") {
+		width = max(width, text.StringWidthWithoutEscSequences(line))
+	}
+	return width
+}
+
+func TestTableRendererFitsWidth(t *testing.T) {
+	for _, style := range brewls.TableStyles() {
+		t.Run(style, func(t *testing.T) {
+			out := renderTable(t, brewls.TableRenderer{Style: style, Width: 80, Color: true}, brewls.DefaultViewOptions())
+			if width := maxLineWidth(out); width > 80 {
+				t.Errorf("Expected lines of at most 80 columns, got %d:\n%s", width, out)
+			}
+			if !strings.Contains(out, "tool00, tool01") || !strings.Contains(out, " more") {
+				t.Errorf("Expected a truncated Installed By list, got:\n%s", out)
+			}
+		})
+	}
+
+	out := renderTable(t, brewls.TableRenderer{}, brewls.DefaultViewOptions())
+	if !strings.Contains(out, "tool28, tool29") || strings.Contains(out, " more") {
+		t.Errorf("Expected the full list without a width, got:\n%s", out)
+	}
+}
+
+func TestTableRendererColumnWidth(t *testing.T) {
+	options := brewls.DefaultViewOptions()
+	for i := range options.Columns {
+		if options.Columns[i].Key == "installed_by" {
+			options.Columns[i].Width = 23
+		}
+	}
+	out := renderTable(t, brewls.TableRenderer{}, options)
+	if !strings.Contains(out, "| tool00, tool01 +28 more |") {
+		t.Errorf("Expected Installed By cut to 23 columns, got:\n%s", out)
+	}
+
+	// A width narrower than one item still shows the first one.
+	options.Columns[2].Width = 3
+	out = renderTable(t, brewls.TableRenderer{Width: 200}, options)
+	if !strings.Contains(out, "| tool00 +29 more |") {
+		t.Errorf("Expected the first item and a count, got:\n%s", out)
+	}
+
+	// A single item is cut rather than followed by "+0 more".
+	info := &brewls.BrewInfo{Formulae: []brewls.Formula{
+		{Name: "zlib", Installed: []brewls.Installed{{Version: "1.3.1"}}},
+		{Name: "a-formula-with-a-long-name", Installed: []brewls.Installed{{Version: "1.0", InstalledOnRequest: true,
+			RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "zlib"}}}}},
+	}}
+	brewls.BuildReverseDependencyGraph(info)
+	options.Columns[2].Width = 10
+	var buf bytes.Buffer
+	if err := (brewls.TableRenderer{Width: 200}).Render(brewls.NewView(info, options), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "| a-formula…   |") || strings.Contains(out, "more") {
+		t.Errorf("Expected the single item cut with an ellipsis, got:\n%s", out)
+	}
+}
@@ -0,0 +1,89 @@
+package brewls
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Table styles accepted by TableRenderer.
+const (
+	TableStyleDefault  = "default"  // ASCII borders, as brewls has always drawn them
+	TableStyleLight    = "light"    // Thin box-drawing borders
+	TableStyleRounded  = "rounded"  // Box-drawing borders with rounded corners
+	TableStyleMarkdown = "markdown" // Pipe tables, never colored
+	TableStyleNone     = "none"     // No borders or separators, just aligned columns
+)
+
+// TableStyles returns the table style names in the order shown in help.
+func TableStyles() []string {
+	return []string{TableStyleDefault, TableStyleLight, TableStyleRounded, TableStyleMarkdown, TableStyleNone}
+}
+
+func goPrettyStyle(name string) table.Style {
+	switch name {
+	case TableStyleLight:
+		return table.StyleLight
+	case TableStyleRounded:
+		return table.StyleRounded
+	case TableStyleNone:
+		style := table.StyleLight
+		style.Options = table.OptionsNoBordersAndSeparators
+		return style
+	default:
+		return table.StyleDefault
+	}
+}
+
+// Colors used by TableRenderer when Color is set.
+var (
+	colorHeader     = text.Colors{text.Bold}
+	colorRoot       = text.Colors{text.Bold, text.FgGreen}
+	colorOutdated   = text.Colors{text.FgYellow}
+	colorDeprecated = text.Colors{text.FgRed}
+	colorDependency = text.Colors{text.Faint}
+)
+
+// paint wraps s in the escape sequences for colors. Unlike text.Colors.Sprint it
+// ignores go-pretty's global color switch; callers decide with --color.
+func paint(colors text.Colors, s string) string {
+	if len(colors) == 0 || s == "" {
+		return s
+	}
+	return colors.EscapeSeq() + s + text.Reset.EscapeSeq()
+}
+
+// colorCells colors the cells of one table row: deprecated or disabled packages
+// in red, packages only installed as dependencies dimmed, root package names
+// highlighted and outdated versions in yellow.
+func colorCells(columns []Column, row Row, cells []string) []string {
+	var rowColors text.Colors
+	switch {
+	case row.Deprecated || row.Disabled:
+		rowColors = colorDeprecated
+	case !row.IsRoot && len(row.InstalledBy) > 0:
+		rowColors = colorDependency
+	}
+
+	colored := make([]string, len(cells))
+	for i, cell := range cells {
+		colors := rowColors
+		if i < len(columns) {
+			switch {
+			case columns[i].Key == "version" && row.Outdated:
+				colors = colorOutdated
+			case columns[i].Key == "name" && row.IsRoot && rowColors == nil:
+				colors = colorRoot
+			}
+		}
+		colored[i] = paint(colors, cell)
+	}
+	return colored
+}
+
+func colorHeaderCells(cells []string) []string {
+	colored := make([]string, len(cells))
+	for i, cell := range cells {
+		colored[i] = paint(colorHeader, cell)
+	}
+	return colored
+}
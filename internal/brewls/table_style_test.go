@@ -0,0 +1,66 @@
+package brewls_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestTableRendererColor(t *testing.T) {
+	info := sampleBrewInfo()
+	info.Casks[0].Deprecated = true
+
+	var buf bytes.Buffer
+	if err := (brewls.TableRenderer{Color: true}).Render(brewls.NewView(info, brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	out := buf.String()
+
+	for name, want := range map[string]string{
+		"bold header":           "\x1b[1mNAME\x1b[0m",
+		"highlighted root":      "\x1b[1;32mgit *\x1b[0m",
+		"dimmed dependency":     "\x1b[2mpcre2\x1b[0m",
+		"yellow outdated":       "\x1b[33m10.42\x1b[0m",
+		"red deprecated":        "\x1b[31mMozilla Firefox *\x1b[0m",
+		"aligned after escapes": "| \x1b[2mpcre2\x1b[0m | \x1b[33m10.42\x1b[0m   |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s %q in:\n%s", name, want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := (brewls.TableRenderer{}).Render(brewls.NewView(info, brewls.DefaultViewOptions()), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("Expected no escape sequences without Color, got:\n%q", buf.String())
+	}
+}
+
+func TestTableRendererStyles(t *testing.T) {
+	tests := map[string]string{
+		brewls.TableStyleDefault:  "+-------+",
+		brewls.TableStyleLight:    "┌───────┬",
+		brewls.TableStyleRounded:  "╭───────┬",
+		brewls.TableStyleMarkdown: "| Name | Version | Installed By |\n| --- | --- | --- |",
+		brewls.TableStyleNone:     " NAME   VERSION  INSTALLED BY \n git *  2.44.0",
+	}
+	for style, want := range tests {
+		t.Run(style, func(t *testing.T) {
+			var buf bytes.Buffer
+			renderer := brewls.TableRenderer{Style: style, Color: style == brewls.TableStyleMarkdown}
+			if err := renderer.Render(brewls.NewView(sampleBrewInfo(), brewls.DefaultViewOptions()), &buf); err != nil {
+				t.Fatalf("Render returned error: %v", err)
+			}
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("Expected %q in:\n%s", want, buf.String())
+			}
+			if strings.Contains(buf.String(), "\x1b[") {
+				t.Errorf("Expected no escape sequences, got:\n%q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package brewls
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// TemplateRenderer executes a text/template once per row, formulae before
+// casks, and ends each result with a newline. The template's dot is the Row,
+// which also carries the full Formula or Cask, and TemplateFuncs are available.
+type TemplateRenderer struct {
+	text string
+	tmpl *template.Template
+}
+
+// TemplateFuncs lists the functions available to templates besides the text/template
+// built-ins. The graph helpers take a package name and look at the whole
+// installation, so they also work for packages outside the listing.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":     func(items []string, sep string) string { return strings.Join(items, sep) },
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"humanize": HumanizeBytes,
+		"date":     formatDate,
+		// Replaced with functions bound to the view in Render.
+		"dependents": func(string) []string { return nil },
+		"deps":       func(string) []string { return nil },
+		"isRoot":     func(string) bool { return false },
+	}
+}
+
+// formatDate formats t with a Go time layout, "2006-01-02" by default. The zero
+// time, used when brew did not report one, formats as "".
+func formatDate(t time.Time, layout ...string) string {
+	if t.IsZero() {
+		return ""
+	}
+	if len(layout) > 0 {
+		return t.Local().Format(layout[0])
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// TemplateError reports a problem with a template and where it occurred.
+type TemplateError struct {
+	Text string // the full template
+	Line int    // 1-based line of the problem
+	Col  int    // 1-based column of the problem
+	Msg  string
+}
+
+func (e *TemplateError) Error() string {
+	lines := strings.Split(e.Text, "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return fmt.Sprintf("invalid template: %s at line %d", e.Msg, e.Line)
+	}
+	return fmt.Sprintf("invalid template: %s at line %d, column %d\n  %s\n  %s^",
+		e.Msg, e.Line, e.Col, lines[e.Line-1], strings.Repeat(" ", max(e.Col-1, 0)))
+}
+
+// text/template reports parse errors as "template: NAME:LINE: MSG" and execution
+// errors as "template: NAME:LINE:COL: MSG".
+var (
+	templateParseErrorRE = regexp.MustCompile(`^template: [^:]*:(\d+): (.*)$`)
+	templateExecErrorRE  = regexp.MustCompile(`^template: [^:]*:(\d+):(\d+): (.*)$`)
+	quotedRE             = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// NewTemplateRenderer parses text as a row template. Errors are *TemplateError.
+func NewTemplateRenderer(text string) (*TemplateRenderer, error) {
+	tmpl, err := template.New("template").Option("missingkey=error").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, templateError(text, err, false)
+	}
+	return &TemplateRenderer{text: text, tmpl: tmpl}, nil
+}
+
+// templateError converts a text/template error into a *TemplateError. Parse
+// errors carry no column, so it points at the token the message quotes, or
+// else at the last action opened on the line.
+func templateError(text string, err error, exec bool) error {
+	msg := err.Error()
+	if exec {
+		m := templateExecErrorRE.FindStringSubmatch(msg)
+		if m == nil {
+			return err
+		}
+		line, _ := strconv.Atoi(m[1])
+		col, _ := strconv.Atoi(m[2])
+		return &TemplateError{Text: text, Line: line, Col: col + 1, Msg: m[3]}
+	}
+
+	m := templateParseErrorRE.FindStringSubmatch(msg)
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	e := &TemplateError{Text: text, Line: line, Col: 1, Msg: m[2]}
+	if lines := strings.Split(text, "\n"); line >= 1 && line <= len(lines) {
+		source := lines[line-1]
+		if q := quotedRE.FindStringSubmatch(e.Msg); q != nil && strings.Contains(source, q[1]) {
+			e.Col = strings.Index(source, q[1]) + 1
+		} else if i := strings.LastIndex(source, "{{"); i >= 0 {
+			e.Col = i + 1
+		}
+	}
+	return e
+}
+
+// UsesField reports whether the template refers to a field or method with the
+// given name, such as SizeBytes, so callers can compute it only when needed.
+func (r *TemplateRenderer) UsesField(name string) bool {
+	found := false
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, child := range n.Nodes {
+					walk(child)
+				}
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n != nil {
+				for _, cmd := range n.Cmds {
+					walk(cmd)
+				}
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			found = found || slices.Contains(n.Ident, name)
+		case *parse.ChainNode:
+			found = found || slices.Contains(n.Field, name)
+			walk(n.Node)
+		case *parse.VariableNode:
+			found = found || slices.Contains(n.Ident[1:], name)
+		case *parse.IfNode:
+			walk(&n.BranchNode)
+		case *parse.RangeNode:
+			walk(&n.BranchNode)
+		case *parse.WithNode:
+			walk(&n.BranchNode)
+		case *parse.BranchNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+	for _, t := range r.tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+	return found
+}
+
+// Render implements Renderer.
+func (r *TemplateRenderer) Render(view *View, writer io.Writer) error {
+	info := view.Installation
+	if info == nil {
+		info = &BrewInfo{}
+	}
+	deps := InstalledDependencies(info)
+	dependents := make(map[string][]string, len(info.Formulae)+len(info.Casks))
+	roots := make(map[string]bool, len(info.Formulae)+len(info.Casks))
+	for _, f := range info.Formulae {
+		dependents[f.Name], roots[f.Name] = f.InstalledBy, f.IsRoot
+	}
+	for _, c := range info.Casks {
+		dependents[c.Token], roots[c.Token] = c.InstalledBy, c.IsRoot
+	}
+
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"dependents": func(name string) []string { return dependents[name] },
+		"deps":       func(name string) []string { return deps[name] },
+		"isRoot":     func(name string) bool { return roots[name] },
+	})
+
+	var buf bytes.Buffer
+	for _, row := range view.Rows() {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, row); err != nil {
+			return templateError(r.text, err, true)
+		}
+		buf.WriteByte('\n')
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package brewls_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func renderTemplate(t *testing.T, text string, info *brewls.BrewInfo) string {
+	t.Helper()
+	renderer, err := brewls.NewTemplateRenderer(text)
+	if err != nil {
+		t.Fatalf("NewTemplateRenderer returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(brewls.NewView(info, brewls.ViewOptions{}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	info := sampleBrewInfo()
+	info.Formulae[0].Installed[0].Time = time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local).Unix()
+	info.Formulae[1].SizeBytes = 1536
+
+	tests := []struct {
+		name, text, want string
+	}{
+		{"fields", `{{.Name}} {{.Version}} {{join .InstalledBy ","}}`, "git 2.44.0 \npcre2 10.42 git\nfirefox 125.0 \n"},
+		{"functions", `{{upper .Name}} {{humanize .SizeBytes}} {{date .InstalledAt}}`, "GIT 0 B 2024-03-01\nPCRE2 1.5 KiB \nFIREFOX 0 B \n"},
+		{"source data", `{{if .Formula}}{{.Formula.Tap}}{{else}}{{index .Cask.Name 0}}{{end}}`, "homebrew/core\nhomebrew/core\nMozilla Firefox\n"},
+		{"graph", `{{.Name}}: deps={{deps .Name}} dependents={{dependents .Name}} root={{isRoot .Name}}`, "git: deps=[pcre2] dependents=[] root=true\npcre2: deps=[] dependents=[git] root=false\nfirefox: deps=[] dependents=[] root=true\n"},
+		{"graph by name", `{{range deps "git"}}{{.}}{{end}}`, "pcre2\npcre2\npcre2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTemplate(t, tt.text, info); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateRendererUsesInstallation(t *testing.T) {
+	info := sampleBrewInfo()
+	selector, err := brewls.NewSelector([]string{"git"}, nil, false)
+	if err != nil {
+		t.Fatalf("NewSelector returned error: %v", err)
+	}
+	installation := *info
+	if err := selector.Restrict(info); err != nil {
+		t.Fatalf("Restrict returned error: %v", err)
+	}
+
+	renderer, err := brewls.NewTemplateRenderer(`{{.Name}} {{deps .Name}}`)
+	if err != nil {
+		t.Fatalf("NewTemplateRenderer returned error: %v", err)
+	}
+	view := brewls.NewView(info, brewls.ViewOptions{})
+	view.Installation = &installation
+	var buf bytes.Buffer
+	if err := renderer.Render(view, &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if want := "git [pcre2]\n"; buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	tests := []struct {
+		text      string
+		msg       string
+		line, col int
+	}{
+		{text: `{{.Name}} {{nope .Name}}`, msg: `function "nope" not defined`, line: 1, col: 13},
+		{text: "{{.Name}}\n{{.Version}} {{.Name", msg: `unclosed action`, line: 2, col: 14},
+		{text: "{{if .IsRoot}}x", msg: `unexpected EOF`, line: 1, col: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			_, err := brewls.NewTemplateRenderer(tt.text)
+			var templateErr *brewls.TemplateError
+			if !errors.As(err, &templateErr) {
+				t.Fatalf("Expected a TemplateError, got %v", err)
+			}
+			if !strings.Contains(templateErr.Msg, tt.msg) || templateErr.Line != tt.line || templateErr.Col != tt.col {
+				t.Errorf("Expected %q at %d:%d, got %q at %d:%d", tt.msg, tt.line, tt.col, templateErr.Msg, templateErr.Line, templateErr.Col)
+			}
+		})
+	}
+
+	renderer, err := brewls.NewTemplateRenderer("{{.Name}}\n  {{.Nope}}")
+	if err != nil {
+		t.Fatalf("NewTemplateRenderer returned error: %v", err)
+	}
+	err = renderer.Render(brewls.NewView(sampleBrewInfo(), brewls.ViewOptions{}), &bytes.Buffer{})
+	var templateErr *brewls.TemplateError
+	if !errors.As(err, &templateErr) || templateErr.Line != 2 || templateErr.Col != 5 || !strings.Contains(templateErr.Msg, "can't evaluate field Nope") {
+		t.Fatalf("Expected an execution error at 2:5, got %#v", err)
+	}
+	if want := "at line 2, column 5\n    {{.Nope}}\n      ^"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected %q in %q", want, err.Error())
+	}
+}
+
+func TestTemplateUsesField(t *testing.T) {
+	for text, want := range map[string]bool{
+		`{{humanize .SizeBytes}}`:                      true,
+		`{{with .Formula}}{{.SizeBytes}}{{end}}`:       true,
+		`{{if .IsRoot}}{{.Formula.SizeBytes}}{{end}}`:  true,
+		`{{.Name}} {{range .InstalledBy}}{{.}}{{end}}`: false,
+	} {
+		renderer, err := brewls.NewTemplateRenderer(text)
+		if err != nil {
+			t.Fatalf("NewTemplateRenderer(%q) returned error: %v", text, err)
+		}
+		if got := renderer.UsesField("SizeBytes"); got != want {
+			t.Errorf("UsesField(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package brewls
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"brewls/pkg/version"
+)
+
+// Upgrade is a package whose version changed, or would change when upgraded,
+// with the kind of bump and what it affects.
+type Upgrade struct {
+	Type string `json:"type"` // PackageTypeFormula or PackageTypeCask
+	Name string `json:"name"`
+	// Replaces is the versioned formula this one took over from, e.g.
+	// python@3.11 for python@3.12, when the upgrade switched formulae.
+	Replaces string       `json:"replaces,omitempty"`
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Bump     version.Bump `json:"bump"`
+	// Dependents are the installed packages that depend on this one, directly
+	// or through other packages, sorted.
+	Dependents []string `json:"dependents"`
+}
+
+// PendingUpgrades lists the outdated packages in info whose latest version
+// brew reported, from the installed version to the latest.
+func PendingUpgrades(info *BrewInfo) []Upgrade {
+	installedBy := make(map[string][]string, len(info.Formulae)+len(info.Casks))
+	for _, f := range info.Formulae {
+		installedBy[f.Name] = f.InstalledBy
+	}
+	for _, c := range info.Casks {
+		installedBy[c.Token] = c.InstalledBy
+	}
+
+	upgrades := []Upgrade{}
+	add := func(typ, name, from, to string) {
+		if bump := version.Classify(from, to); bump != version.None {
+			upgrades = append(upgrades, Upgrade{
+				Type: typ, Name: name, From: from, To: to, Bump: bump,
+				Dependents: dependentsOf(installedBy, name),
+			})
+		}
+	}
+	for _, f := range info.Formulae {
+		if latest := f.LatestVersion(); f.Outdated && latest != "" && version.Compare(latest, f.InstalledVersion()) > 0 {
+			add(PackageTypeFormula, f.Name, f.InstalledVersion(), latest)
+		}
+	}
+	for _, c := range info.Casks {
+		if c.Outdated && c.Version != "" && version.Compare(c.Version, c.Installed) > 0 {
+			add(PackageTypeCask, c.Token, c.Installed, c.Version)
+		}
+	}
+	sortUpgrades(upgrades)
+	return upgrades
+}
+
+// SnapshotUpgrades lists the packages whose version changed from snapshot a to
+// the newer snapshot b. A versioned formula replaced by another of the same
+// name, e.g. python@3.11 by python@3.12 or node@20 by node, counts as an
+// upgrade of the newer formula.
+func SnapshotUpgrades(a, b *Snapshot) []Upgrade {
+	installedBy := make(map[string][]string, len(b.Packages))
+	for _, p := range b.Packages {
+		installedBy[p.Name] = p.InstalledBy
+	}
+	before := packagesByKey(a)
+	after := packagesByKey(b)
+
+	// Formulae only in a, by name without the @ version, for pairing with
+	// formulae only in b.
+	removed := make(map[string][]SnapshotPackage)
+	for _, p := range a.Packages {
+		if _, ok := after[p.key()]; !ok && p.Type == PackageTypeFormula {
+			base := unversionedName(p.Name)
+			removed[base] = append(removed[base], p)
+		}
+	}
+
+	upgrades := []Upgrade{}
+	for _, p := range b.Packages {
+		upgrade := Upgrade{Type: p.Type, Name: p.Name, To: p.Version}
+		if old, ok := before[p.key()]; ok {
+			upgrade.From = old.Version
+		} else if candidates := removed[unversionedName(p.Name)]; p.Type == PackageTypeFormula && len(candidates) > 0 {
+			i := 0 // Pair with the newest of several removed versions
+			for j, c := range candidates {
+				if version.Compare(c.Version, candidates[i].Version) > 0 {
+					i = j
+				}
+			}
+			old := candidates[i]
+			removed[unversionedName(p.Name)] = slices.Delete(candidates, i, i+1)
+			upgrade.From, upgrade.Replaces = old.Version, old.Name
+		} else {
+			continue
+		}
+		if upgrade.Bump = version.Classify(upgrade.From, upgrade.To); upgrade.Bump == version.None {
+			continue
+		}
+		upgrade.Dependents = dependentsOf(installedBy, p.Name)
+		upgrades = append(upgrades, upgrade)
+	}
+	sortUpgrades(upgrades)
+	return upgrades
+}
+
+// unversionedName drops the "@version" of a versioned formula name.
+func unversionedName(name string) string {
+	base, _, _ := strings.Cut(name, "@")
+	return base
+}
+
+// dependentsOf returns everything that depends on name through the
+// installedBy edges, without name itself when a cycle leads back to it.
+func dependentsOf(installedBy map[string][]string, name string) []string {
+	return slices.DeleteFunc(TransitiveClosure(installedBy, []string{name}), func(n string) bool { return n == name })
+}
+
+func sortUpgrades(upgrades []Upgrade) {
+	slices.SortStableFunc(upgrades, func(a, b Upgrade) int {
+		if c := slices.Index(version.Bumps, a.Bump) - slices.Index(version.Bumps, b.Bump); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// bumpHeadings titles the groups of WriteUpgradeReport.
+var bumpHeadings = map[version.Bump]string{
+	version.Major:     "Major upgrades (may break dependents)",
+	version.Minor:     "Minor upgrades",
+	version.Patch:     "Patch upgrades",
+	version.Revision:  "Rebuilds (revision only)",
+	version.Date:      "Date-versioned upgrades",
+	version.Other:     "Other version changes",
+	version.Downgrade: "Downgrades",
+}
+
+// WriteUpgradeReport prints upgrades grouped by kind of bump, most disruptive
+// first. Major upgrades are marked with "!" and list every dependent; other
+// upgrades only count them.
+func WriteUpgradeReport(writer io.Writer, upgrades []Upgrade) error {
+	var b strings.Builder
+	for _, bump := range version.Bumps {
+		group := slices.DeleteFunc(slices.Clone(upgrades), func(u Upgrade) bool { return u.Bump != bump })
+		if len(group) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s: %d\n", bumpHeadings[bump], len(group))
+		for _, u := range group {
+			marker := " "
+			if bump == version.Major {
+				marker = "!"
+			}
+			fmt.Fprintf(&b, "%s %s %s → %s", marker, u.Name, u.From, u.To)
+			if u.Replaces != "" {
+				fmt.Fprintf(&b, " (replaces %s)", u.Replaces)
+			}
+			switch {
+			case len(u.Dependents) == 0:
+			case bump == version.Major:
+				fmt.Fprintf(&b, "\n    affects %s", strings.Join(u.Dependents, ", "))
+			default:
+				fmt.Fprintf(&b, ", %s", plural(len(u.Dependents), "dependent", "dependents"))
+			}
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
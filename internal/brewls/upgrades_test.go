@@ -0,0 +1,71 @@
+package brewls_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"brewls/internal/brewls"
+	"brewls/pkg/version"
+)
+
+func TestPendingUpgrades(t *testing.T) {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "node", Outdated: true, Versions: brewls.Versions{Stable: "22.3.0"}, Installed: []brewls.Installed{{Version: "20.11.0", RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "icu4c"}}}}},
+			{Name: "icu4c", Outdated: true, Versions: brewls.Versions{Stable: "74.2"}, Revision: 1, Installed: []brewls.Installed{{Version: "74.2"}}},
+			{Name: "yarn", Installed: []brewls.Installed{{Version: "1.22.22", InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "node"}}}}},
+			{Name: "git", Versions: brewls.Versions{Stable: "2.45.0"}, Installed: []brewls.Installed{{Version: "2.44.0"}}}, // not outdated
+		},
+		Casks: []brewls.Cask{{Token: "firefox", Outdated: true, Installed: "125.0", Version: "125.0.1"}},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+
+	expected := []brewls.Upgrade{
+		{Type: "formula", Name: "node", From: "20.11.0", To: "22.3.0", Bump: version.Major, Dependents: []string{"yarn"}},
+		{Type: "cask", Name: "firefox", From: "125.0", To: "125.0.1", Bump: version.Patch, Dependents: []string{}},
+		{Type: "formula", Name: "icu4c", From: "74.2", To: "74.2_1", Bump: version.Revision, Dependents: []string{"node", "yarn"}},
+	}
+	upgrades := brewls.PendingUpgrades(info)
+	if !reflect.DeepEqual(upgrades, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, upgrades)
+	}
+
+	var buf bytes.Buffer
+	if err := brewls.WriteUpgradeReport(&buf, upgrades); err != nil {
+		t.Fatalf("WriteUpgradeReport returned error: %v", err)
+	}
+	want := `Major upgrades (may break dependents): 1
+! node 20.11.0 → 22.3.0
+    affects yarn
+
+Patch upgrades: 1
+  firefox 125.0 → 125.0.1
+
+Rebuilds (revision only): 1
+  icu4c 74.2 → 74.2_1, 2 dependents
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, buf.String())
+	}
+}
+
+func TestSnapshotUpgrades(t *testing.T) {
+	before := &brewls.Snapshot{Packages: []brewls.SnapshotPackage{
+		{Type: "formula", Name: "git", Version: "2.44.0"},
+		{Type: "formula", Name: "python@3.11", Version: "3.11.9"},
+		{Type: "formula", Name: "tree", Version: "2.1.1"},
+	}}
+	after := &brewls.Snapshot{Packages: []brewls.SnapshotPackage{
+		{Type: "formula", Name: "git", Version: "2.44.0_1"},
+		{Type: "formula", Name: "python@3.12", Version: "3.12.4", InstalledBy: []string{"pipx"}},
+		{Type: "formula", Name: "pipx", Version: "1.6.0"},
+	}}
+	expected := []brewls.Upgrade{
+		{Type: "formula", Name: "python@3.12", Replaces: "python@3.11", From: "3.11.9", To: "3.12.4", Bump: version.Minor, Dependents: []string{"pipx"}},
+		{Type: "formula", Name: "git", From: "2.44.0", To: "2.44.0_1", Bump: version.Revision, Dependents: []string{}},
+	}
+	if upgrades := brewls.SnapshotUpgrades(before, after); !reflect.DeepEqual(upgrades, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, upgrades)
+	}
+}
@@ -0,0 +1,215 @@
+package brewls
+
+import "time"
+
+// rootMarker is appended to root package names in plain-text output.
+const rootMarker = " *"
+
+// Row is the presentation-ready view of a single formula or cask.
+// Renderers read typed fields from Row instead of reaching into Formula and Cask.
+type Row struct {
+	Type        string   // PackageTypeFormula or PackageTypeCask
+	Name        string   // formula name or cask token
+	DisplayName string   // cask Name[0] when available, otherwise Name
+	Version     string   // installed version, "" when unknown
+	InstalledBy []string // sorted names of installed packages that depend on this one
+	IsRoot      bool
+	Orphan      bool // formula installed as a dependency that nothing needs any more
+	FullName    string
+	Tap         string
+	Desc        string
+	Homepage    string
+	License     string // formula license as brew reports it; brew has none for casks
+	Outdated    bool
+	Deprecated  bool
+	Disabled    bool
+	InstalledAt time.Time // zero when brew did not report an install time
+	SizeBytes   int64     // zero unless PopulateDiskUsage was called
+
+	Formula *Formula // source data; nil for casks
+	Cask    *Cask    // source data; nil for formulae
+}
+
+// Section is a titled group of rows, such as all formulae.
+type Section struct {
+	Title string
+	Type  string
+	Rows  []Row
+}
+
+// View is the renderer-independent model of a listing.
+type View struct {
+	Formulae []Row
+	Casks    []Row
+	Columns  []Column
+
+	// Installation is the BrewInfo the rows describe, for renderers that follow
+	// dependencies beyond the listed rows. NewView sets it to its argument;
+	// callers that restrict BrewInfo to a selection set it to the whole one.
+	Installation *BrewInfo
+}
+
+// ViewOptions controls how NewView builds a View.
+type ViewOptions struct {
+	Sort    []SortKey // rows keep brew's order when empty
+	Columns []Column  // DefaultColumns when empty
+	Filter  *Filter   // rows that do not match are dropped; nil keeps every row
+}
+
+// DefaultViewOptions returns the options selected by the current feature flags and
+// ColumnsEnv. Unknown column keys are skipped; callers that take columns from the
+// user should use ResolveColumns to report them instead.
+func DefaultViewOptions() ViewOptions {
+	opts := ViewOptions{Columns: knownColumns(DefaultColumns())}
+	if IsFeatureEnabled(featureSortOutput) {
+		opts.Sort, _ = ParseSortKeys("name")
+	}
+	return opts
+}
+
+func knownColumns(keys []string) []Column {
+	var result []Column
+	for _, key := range keys {
+		if c, ok := LookupColumn(key); ok {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// NewView converts BrewInfo into rows for rendering.
+// BuildReverseDependencyGraph should be called first so InstalledBy and IsRoot are populated.
+func NewView(brewInfo *BrewInfo, opts ViewOptions) *View {
+	view := &View{
+		Formulae:     make([]Row, 0, len(brewInfo.Formulae)),
+		Casks:        make([]Row, 0, len(brewInfo.Casks)),
+		Columns:      opts.Columns,
+		Installation: brewInfo,
+	}
+	if len(view.Columns) == 0 {
+		view.Columns = knownColumns(DefaultColumns())
+	}
+
+	for i := range brewInfo.Formulae {
+		if row := FormulaRow(&brewInfo.Formulae[i]); opts.Filter.Match(row) {
+			view.Formulae = append(view.Formulae, row)
+		}
+	}
+	for i := range brewInfo.Casks {
+		if row := CaskRow(&brewInfo.Casks[i]); opts.Filter.Match(row) {
+			view.Casks = append(view.Casks, row)
+		}
+	}
+
+	SortRows(view.Formulae, opts.Sort)
+	SortRows(view.Casks, opts.Sort)
+
+	return view
+}
+
+// FormulaRow builds the Row for a formula.
+func FormulaRow(f *Formula) Row {
+	row := Row{
+		Type:        PackageTypeFormula,
+		Name:        f.Name,
+		DisplayName: f.Name,
+		Version:     f.InstalledVersion(),
+		InstalledBy: UniqueAndSortStrings(f.InstalledBy),
+		IsRoot:      f.IsRoot,
+		Orphan:      f.IsOrphan(),
+		FullName:    f.FullName,
+		Tap:         f.Tap,
+		Desc:        f.Desc,
+		Homepage:    f.Homepage,
+		License:     f.License,
+		Outdated:    f.Outdated,
+		Deprecated:  f.Deprecated,
+		Disabled:    f.Disabled,
+		SizeBytes:   f.SizeBytes,
+		Formula:     f,
+	}
+	if len(f.Installed) > 0 && f.Installed[len(f.Installed)-1].Time > 0 {
+		row.InstalledAt = time.Unix(f.Installed[len(f.Installed)-1].Time, 0)
+	}
+	return row
+}
+
+// CaskRow builds the Row for a cask.
+func CaskRow(c *Cask) Row {
+	displayName := c.Token
+	if len(c.Name) > 0 {
+		displayName = c.Name[0]
+	}
+	row := Row{
+		Type:        PackageTypeCask,
+		Name:        c.Token,
+		DisplayName: displayName,
+		Version:     c.Installed,
+		InstalledBy: UniqueAndSortStrings(c.InstalledBy),
+		IsRoot:      c.IsRoot,
+		Tap:         c.Tap,
+		Desc:        c.Desc,
+		Homepage:    c.Homepage,
+		Outdated:    c.Outdated,
+		Deprecated:  c.Deprecated,
+		Disabled:    c.Disabled,
+		SizeBytes:   c.SizeBytes,
+		Cask:        c,
+	}
+	if c.InstalledTime > 0 {
+		row.InstalledAt = time.Unix(c.InstalledTime, 0)
+	}
+	return row
+}
+
+// Sections returns the formulae and casks groups in display order.
+func (v *View) Sections() []Section {
+	return []Section{
+		{Title: "Homebrew Formulae", Type: PackageTypeFormula, Rows: v.Formulae},
+		{Title: "Homebrew Casks", Type: PackageTypeCask, Rows: v.Casks},
+	}
+}
+
+// Rows returns every row, formulae first.
+func (v *View) Rows() []Row {
+	return append(append([]Row(nil), v.Formulae...), v.Casks...)
+}
+
+// Header returns the column headers shared by the tabular renderers.
+func (v *View) Header() []string {
+	header := make([]string, len(v.Columns))
+	for i, c := range v.Columns {
+		header[i] = c.Header
+	}
+	return header
+}
+
+// Cells formats a row for the tabular renderers. The name cell carries no root
+// marker; each renderer decorates root rows in its own way.
+// listSeparator joins list values such as Installed By within a single cell.
+func (v *View) Cells(row Row, listSeparator string) []string {
+	cells := make([]string, len(v.Columns))
+	for i, c := range v.Columns {
+		cells[i] = c.Text(row, listSeparator)
+	}
+	return cells
+}
+
+// NameColumn returns the index of the name column, or -1 when it is not shown.
+func (v *View) NameColumn() int {
+	for i, c := range v.Columns {
+		if c.Key == "name" {
+			return i
+		}
+	}
+	return -1
+}
+
+// markedCells returns Cells with the plain-text root marker appended to the name.
+func (v *View) markedCells(row Row, listSeparator string) []string {
+	cells := v.Cells(row, listSeparator)
+	if i := v.NameColumn(); row.IsRoot && i >= 0 {
+		cells[i] += rootMarker
+	}
+	return cells
+}
@@ -0,0 +1,107 @@
+package brewls_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"brewls/internal/brewls"
+)
+
+func TestNewView(t *testing.T) {
+	info := delimitedTestInfo()
+	info.Casks = append(info.Casks, brewls.Cask{Token: "alacritty", Installed: "0.13.2"})
+
+	view := brewls.NewView(info, brewls.ViewOptions{Sort: mustParseSortKeys(t, "name")})
+
+	var names []string
+	for _, row := range view.Rows() {
+		names = append(names, row.Type+"/"+row.Name)
+	}
+	expected := []string{"formula/curl", "formula/libxml2", "formula/zlib", "cask/alacritty", "cask/iterm2"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected rows %v, got %v", expected, names)
+	}
+
+	zlib := view.Formulae[2]
+	if zlib.Version != "1.3.1" || zlib.IsRoot || !reflect.DeepEqual(zlib.InstalledBy, []string{"curl", "libxml2"}) {
+		t.Errorf("Unexpected zlib row: %+v", zlib)
+	}
+	if zlib.Formula == nil || zlib.Cask != nil {
+		t.Errorf("Expected zlib row to reference its formula only")
+	}
+
+	if got := view.Casks[0].DisplayName; got != "alacritty" {
+		t.Errorf("Expected cask without a name to display its token, got %q", got)
+	}
+	if got := view.Casks[1].DisplayName; got != "iTerm2" {
+		t.Errorf("Expected cask display name iTerm2, got %q", got)
+	}
+}
+
+func TestViewCells(t *testing.T) {
+	view := brewls.NewView(&brewls.BrewInfo{
+		Formulae: []brewls.Formula{{Name: "bare"}},
+	}, brewls.ViewOptions{Columns: mustResolveColumns(t, "name", "version", "installed_by", "count")})
+
+	if got, want := view.Header(), []string{"Name", "Version", "Installed By", "Installed By Count"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected header %v, got %v", want, got)
+	}
+	if got, want := view.Cells(view.Formulae[0], ", "), []string{"bare", "N/A", "", "0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected cells %v, got %v", want, got)
+	}
+}
+
+type countRenderer struct{ prefix string }
+
+func (r countRenderer) Render(view *brewls.View, writer io.Writer) error {
+	_, err := fmt.Fprintf(writer, "%s%d formulae, %d casks\n", r.prefix, len(view.Formulae), len(view.Casks))
+	return err
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	brewls.RegisterRenderer("Count", func(opts brewls.RenderOptions) brewls.Renderer {
+		return countRenderer{prefix: opts.ListSeparator}
+	})
+
+	renderer, err := brewls.NewRenderer("count", brewls.RenderOptions{ListSeparator: "> "})
+	if err != nil {
+		t.Fatalf("NewRenderer returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(brewls.NewView(delimitedTestInfo(), brewls.ViewOptions{}), &buf); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if got, want := buf.String(), "> 3 formulae, 1 casks\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	found := false
+	for _, format := range brewls.Formats() {
+		found = found || format == "count"
+	}
+	if !found {
+		t.Errorf("Expected Formats() to include the registered renderer, got %v", brewls.Formats())
+	}
+}
+
+func mustResolveColumns(t *testing.T, keys ...string) []brewls.Column {
+	t.Helper()
+	columns, err := brewls.ResolveColumns(keys)
+	if err != nil {
+		t.Fatalf("ResolveColumns(%v) returned error: %v", keys, err)
+	}
+	return columns
+}
+
+func mustParseSortKeys(t *testing.T, raw string) []brewls.SortKey {
+	t.Helper()
+	keys, err := brewls.ParseSortKeys(raw)
+	if err != nil {
+		t.Fatalf("ParseSortKeys(%q) returned error: %v", raw, err)
+	}
+	return keys
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"brewls/internal/brewls"
+)
+
+var checkCommand = &Command{
+	Name:    "check",
+	Summary: "Compare a Brewfile with the installed packages",
+	Help: `Compare the entries of a Brewfile with what is installed and report entries
+that are not installed, root packages the Brewfile does not list, and entries
+installed only as dependencies of something else. Entries brew does not
+install itself, such as mas, whalebrew and vscode, are noted and skipped.
+Exits with status 3 when they differ, so it can run in a login hook or CI.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		path := fs.String("brewfile", "Brewfile", "the Brewfile to check")
+		asJSON := fs.Bool("json", false, "print the differences as JSON")
+		return func(env *Env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("check takes no arguments; name the Brewfile with --brewfile")
+			}
+			file, err := os.Open(*path)
+			if err != nil {
+				return err
+			}
+			entries, err := brewls.ParseBrewfile(file)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", *path, err)
+			}
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+			drift := brewls.CheckBrewfile(info, entries)
+			for _, entry := range drift.Skipped {
+				fmt.Fprintf(env.Stderr, "brewls check: note: line %d: skipped %s; only brew and cask entries are checked\n", entry.Line, entry)
+			}
+
+			if *asJSON {
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(struct {
+					Brewfile string `json:"brewfile"`
+					InSync   bool   `json:"in_sync"`
+					brewls.BrewfileDrift
+				}{*path, drift.InSync(), drift})
+			} else if drift.InSync() {
+				fmt.Fprintf(env.Stdout, "%s is in sync with the installed packages.\n", *path)
+			} else {
+				err = brewls.WriteBrewfileDrift(env.Stdout, drift)
+			}
+			if err != nil {
+				return err
+			}
+			if !drift.InSync() {
+				fmt.Fprintf(env.Stderr, "brewls check: %s differs: %d missing, %d extra, %d only installed as dependencies\n",
+					*path, len(drift.Missing), len(drift.Extra), len(drift.DependencyOnly))
+				return exitStatus(ExitProblems)
+			}
+			return nil
+		}
+	},
+}
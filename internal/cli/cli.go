@@ -0,0 +1,352 @@
+// Package cli implements the brewls command line: subcommands, their flags,
+// help text and exit codes. cmd/brewls only forwards os.Args to Run.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"brewls/internal/brewls"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK       = 0 // Success
+	ExitError    = 1 // brew failed, output could not be written, or a similar runtime error
+	ExitUsage    = 2 // Unknown command, bad flag or invalid argument
+	ExitProblems = 3 // The command ran but found problems, e.g. brewls doctor
+)
+
+// DefaultCommand runs when the first argument is not a command name,
+// so plain `brewls` and `brewls --format json` keep listing packages.
+const DefaultCommand = "list"
+
+// Command is a brewls subcommand.
+type Command struct {
+	Name    string
+	Args    string // Argument synopsis shown after "[flags]" in usage
+	Summary string // One line for the command overview
+	Help    string // Longer description shown by `brewls help <command>`
+	// Setup registers the command's flags and returns the function that runs it
+	// with the remaining positional arguments.
+	Setup func(fs *flag.FlagSet) func(env *Env, args []string) error
+}
+
+// commands lists every subcommand in the order shown by help.
+var commands []*Command
+
+func init() {
+	commands = []*Command{
+		listCommand,
+		treeCommand,
+		whyCommand,
+		graphCommand,
+		exportCommand,
+		doctorCommand,
+		statsCommand,
+		checkCommand,
+		licensesCommand,
+		snapshotCommand,
+		diffCommand,
+		upgradesCommand,
+		historyCommand,
+		flagsCommand,
+		configCommand,
+		versionCommand,
+		helpCommand,
+	}
+}
+
+func lookupCommand(name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// Env carries the output streams, config and brew data source a command runs with.
+type Env struct {
+	Stdout io.Writer
+	Stderr io.Writer
+
+	Config      *brewls.Config
+	ConfigPath  string
+	ConfigFound bool               // Whether ConfigPath existed; otherwise Config is empty
+	ViewName    string             // Set by --view
+	View        *brewls.ConfigView // The view named by --view, or nil
+}
+
+// loadBrewInfo runs brew and builds the reverse dependency graph.
+// It is a variable so tests can supply fixed data.
+var loadBrewInfo = func() (*brewls.BrewInfo, error) {
+	jsonOutput, err := brewls.ExecuteBrewInfoCommand()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute brew command: %w", err)
+	}
+	info, err := brewls.ParseBrewInfoJSON(jsonOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse brew info: %w", err)
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info, nil
+}
+
+// BrewInfo loads the installed packages with their reverse dependency graph,
+// and records what changed since the last run in the history when
+// recordsHistory allows it.
+func (env *Env) BrewInfo() (*brewls.BrewInfo, error) {
+	info, err := loadBrewInfo()
+	if err != nil {
+		return nil, err
+	}
+	if env.recordsHistory() {
+		if _, err := recordHistory(info); err != nil {
+			fmt.Fprintf(env.Stderr, "brewls: warning: could not record history: %v\n", err)
+		}
+	}
+	return info, nil
+}
+
+// recordsHistory reports whether the record-history feature is on for this run.
+// It is on by default only when the output goes to a terminal, so scripts and
+// cron jobs do not write the data directory, or warn that they cannot, unless
+// they turn it on themselves.
+func (env *Env) recordsHistory() bool {
+	for _, state := range brewls.FeatureStates() {
+		if state.Name == brewls.FeatureRecordHistory {
+			return state.Enabled && (state.Source != brewls.FeatureSourceDefault || interactive(env.Stdout))
+		}
+	}
+	return false
+}
+
+// usageError marks errors caused by how brewls was invoked.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitStatus is returned by commands that have already reported their outcome
+// and only need Run to exit with a particular code.
+type exitStatus int
+
+func (e exitStatus) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+// Run executes the command line args (without the program name) and returns
+// the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	env := &Env{Stdout: stdout, Stderr: stderr}
+
+	cmd := lookupCommand(DefaultCommand)
+	if len(args) > 0 {
+		switch args[0] {
+		case "-h", "-help", "--help":
+			writeHelp(stdout)
+			return ExitOK
+		}
+		if named := lookupCommand(args[0]); named != nil {
+			cmd, args = named, args[1:]
+		}
+	}
+
+	return exitCode(env, cmd.Name, runCommand(env, cmd, args))
+}
+
+func runCommand(env *Env, cmd *Command, args []string) error {
+	fs := flag.NewFlagSet("brewls "+cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Errors and help are reported by Run
+	fs.Usage = func() {}
+	globals := addGlobalFlags(fs)
+	run := cmd.Setup(fs)
+
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		writeCommandHelp(env.Stdout, cmd, fs)
+		return nil
+	}
+	if err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	if err := globals.apply(env); err != nil {
+		return err
+	}
+	if globals.version {
+		fmt.Fprintln(env.Stdout, versionLine())
+		return nil
+	}
+	return run(env, positional)
+}
+
+// globalFlags holds the flags every command accepts.
+type globalFlags struct {
+	version  bool
+	features stringList
+	config   string
+	view     string
+}
+
+func addGlobalFlags(fs *flag.FlagSet) *globalFlags {
+	g := &globalFlags{}
+	fs.BoolVar(&g.version, "version", false, "print the brewls version and exit")
+	fs.Var(&g.features, "feature", "enable feature flags, or disable them with a leading -, e.g. sort-output,-installed-by-count (repeatable; see brewls flags)")
+	fs.StringVar(&g.config, "config", "", "read this config file instead of $"+brewls.ConfigEnv+" or ~/.config/brewls/config.json")
+	fs.StringVar(&g.view, "view", "", "apply a named view from the config file")
+	return g
+}
+
+// apply loads the config file and selected view, then hands the config and
+// --feature settings to the feature flag registry and warns about flag names
+// that no feature registered, wherever they were set.
+func (g *globalFlags) apply(env *Env) error {
+	if err := env.loadConfig(g.config, g.view); err != nil {
+		return err
+	}
+
+	brewls.BrewPath = "brew"
+	if env.Config.BrewPath != "" {
+		brewls.BrewPath = env.Config.BrewPath
+	}
+
+	brewls.SetConfigFeatures(env.Config.Features)
+	var viewFeatures map[string]bool
+	if env.View != nil {
+		viewFeatures = env.View.Features
+	}
+	brewls.SetViewFeatures(viewFeatures)
+
+	features := make(map[string]bool)
+	for _, raw := range g.features {
+		maps.Copy(features, brewls.ParseFeatureSettings(raw))
+	}
+	brewls.SetCLIFeatures(features)
+
+	for _, unknown := range brewls.UnknownFeatures() {
+		fmt.Fprintf(env.Stderr, "brewls: warning: unknown feature flag %q (set by %s); run 'brewls flags' for the list\n", unknown.Name, unknown.Source)
+	}
+	return nil
+}
+
+func exitCode(env *Env, name string, err error) int {
+	var status exitStatus
+	var usage *usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &status):
+		return int(status)
+	case errors.As(err, &usage):
+		fmt.Fprintf(env.Stderr, "brewls %s: %v\nRun 'brewls help %s' for usage.\n", name, err, name)
+		return ExitUsage
+	default:
+		fmt.Fprintf(env.Stderr, "brewls %s: %v\n", name, err)
+		return ExitError
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, which the flag package alone stops at. Everything
+// after a "--" argument is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func writeHelp(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("brewls lists installed Homebrew formulae and casks and shows what installed them.\n\n")
+	b.WriteString("Usage:\n  brewls [command] [flags] [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		summary := cmd.Summary
+		if cmd.Name == DefaultCommand {
+			summary += " (default)"
+		}
+		fmt.Fprintf(&b, "  %-9s %s\n", cmd.Name, summary)
+	}
+	b.WriteString("\nGlobal flags:\n")
+	b.WriteString("  -h, --help   show help for brewls or a command\n")
+	b.WriteString("  --version    print the brewls version\n")
+	b.WriteString("  --feature    enable (name) or disable (-name) feature flags\n")
+	b.WriteString("  --config     read this config file\n")
+	b.WriteString("  --view       apply a named view from the config file\n")
+	b.WriteString("\nExit status is 0 on success, 1 on errors, 2 on invalid usage and 3 when a\n")
+	b.WriteString("check such as doctor finds problems.\n\n")
+	b.WriteString("Run 'brewls help <command>' for the flags of a command.\n")
+	io.WriteString(w, b.String())
+}
+
+func writeCommandHelp(w io.Writer, cmd *Command, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: brewls %s [flags]", cmd.Name)
+	if cmd.Args != "" {
+		fmt.Fprintf(w, " %s", cmd.Args)
+	}
+	fmt.Fprintf(w, "\n\n%s\n", cmd.Help)
+
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+}
+
+var helpCommand = &Command{
+	Name:    "help",
+	Args:    "[command]",
+	Summary: "Show help for brewls or a command",
+	Help:    "Show the command overview, or the usage and flags of one command.",
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		return func(env *Env, args []string) error {
+			switch len(args) {
+			case 0:
+				writeHelp(env.Stdout)
+				return nil
+			case 1:
+				cmd := lookupCommand(args[0])
+				if cmd == nil {
+					return usageErrorf("unknown command %q", args[0])
+				}
+				cmdFlags := flag.NewFlagSet("brewls "+cmd.Name, flag.ContinueOnError)
+				addGlobalFlags(cmdFlags)
+				cmd.Setup(cmdFlags)
+				writeCommandHelp(env.Stdout, cmd, cmdFlags)
+				return nil
+			default:
+				return usageErrorf("help takes at most one command name")
+			}
+		}
+	},
+}
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,933 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+func testBrewInfo() *brewls.BrewInfo {
+	info := &brewls.BrewInfo{
+		Formulae: []brewls.Formula{
+			{Name: "git", Installed: []brewls.Installed{{Version: "2.44.0", InstalledOnRequest: true, RuntimeDependencies: []brewls.RuntimeDependency{{FullName: "pcre2", Version: "10.42"}}}}},
+			{Name: "pcre2", Installed: []brewls.Installed{{Version: "10.42"}}},
+			{Name: "tree", Installed: []brewls.Installed{{Version: "2.1.1", InstalledOnRequest: true}}},
+		},
+		Casks: []brewls.Cask{{Token: "firefox", Installed: "125.0"}},
+	}
+	brewls.BuildReverseDependencyGraph(info)
+	return info
+}
+
+// useBrewInfo makes commands see info instead of running brew.
+func useBrewInfo(t *testing.T, info *brewls.BrewInfo, err error) {
+	t.Helper()
+	original := loadBrewInfo
+	loadBrewInfo = func() (*brewls.BrewInfo, error) { return info, err }
+	t.Cleanup(func() { loadBrewInfo = original })
+}
+
+// run runs brewls with args. Unless the test chose a config file, a missing one
+// is used so the developer's own config cannot change the results, and history
+// and snapshots go to a temporary data dir.
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	if os.Getenv(brewls.ConfigEnv) == "" {
+		t.Setenv(brewls.ConfigEnv, filepath.Join(t.TempDir(), "config.json"))
+	}
+	if !strings.HasPrefix(os.Getenv("XDG_DATA_HOME"), os.TempDir()) {
+		t.Setenv("XDG_DATA_HOME", t.TempDir())
+	}
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunHelpDoesNotRunBrew(t *testing.T) {
+	original := loadBrewInfo
+	loadBrewInfo = func() (*brewls.BrewInfo, error) {
+		t.Fatal("help must not run brew")
+		return nil, nil
+	}
+	t.Cleanup(func() { loadBrewInfo = original })
+
+	for _, args := range [][]string{{"-h"}, {"--help"}, {"help"}, {"help", "why"}, {"tree", "-h"}, {"--format", "json", "--help"}, {"--version"}} {
+		code, stdout, _ := run(t, args...)
+		if code != ExitOK {
+			t.Errorf("%v: expected exit %d, got %d", args, ExitOK, code)
+		}
+		if stdout == "" {
+			t.Errorf("%v: expected output", args)
+		}
+	}
+
+	_, stdout, _ := run(t, "help")
+	for _, cmd := range commands {
+		if !strings.Contains(stdout, "  "+cmd.Name+" ") {
+			t.Errorf("Expected help to list %s, got:\n%s", cmd.Name, stdout)
+		}
+	}
+	if _, stdout, _ := run(t, "help", "list"); !strings.Contains(stdout, "Usage: brewls list [flags] [name|glob ...]") || !strings.Contains(stdout, "-columns") {
+		t.Errorf("Expected list usage with flags, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "flags", "-h"); !strings.Contains(stdout, "--feature, the view\nselected with --view, the "+brewls.FeatureFlagsEnv) {
+		t.Errorf("Expected the view in the flags precedence, got:\n%s", stdout)
+	}
+}
+
+func TestRunDefaultsToList(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	code, stdout, stderr := run(t, "--format", "csv", "--columns", "type,name,installed_by")
+	if code != ExitOK {
+		t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+	}
+	expected := "Type,Name,Installed By\nformula,git,\nformula,pcre2,git\nformula,tree,\ncask,firefox,\n"
+	if stdout != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, stdout)
+	}
+}
+
+func TestRunListWithInterspersedFlags(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	// "tree" after "list" is a package name, not the tree command.
+	code, stdout, stderr := run(t, "list", "tree", "--columns", "name", "--format", "tsv", "pcre2")
+	if code != ExitOK {
+		t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+	}
+	if expected := "Name\npcre2\ntree\n"; stdout != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, stdout)
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	tests := []struct {
+		args []string
+		msg  string
+	}{
+		{args: []string{"--nope"}, msg: "brewls list: flag provided but not defined: -nope"},
+		{args: []string{"--format", "yaml"}, msg: `unknown output format "yaml"`},
+		{args: []string{"--filter", "rot"}, msg: `unknown field "rot"`},
+		{args: []string{"why"}, msg: "why needs exactly one package name"},
+		{args: []string{"export"}, msg: "missing export format"},
+		{args: []string{"graph", "--format", "svg"}, msg: `unknown graph format "svg"`},
+		{args: []string{"help", "nope"}, msg: `unknown command "nope"`},
+	}
+	for _, tt := range tests {
+		code, _, stderr := run(t, tt.args...)
+		if code != ExitUsage {
+			t.Errorf("%v: expected exit %d, got %d", tt.args, ExitUsage, code)
+		}
+		if !strings.Contains(stderr, tt.msg) || !strings.Contains(stderr, "Run 'brewls help") {
+			t.Errorf("%v: expected stderr to contain %q, got %q", tt.args, tt.msg, stderr)
+		}
+	}
+}
+
+func TestRunBrewFailure(t *testing.T) {
+	useBrewInfo(t, nil, errors.New("brew not found"))
+
+	code, _, stderr := run(t)
+	if code != ExitError {
+		t.Errorf("Expected exit %d, got %d", ExitError, code)
+	}
+	if stderr != "brewls list: brew not found\n" {
+		t.Errorf("Unexpected stderr %q", stderr)
+	}
+}
+
+func TestRunTreeWhyAndGraph(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	if _, stdout, _ := run(t, "tree"); stdout != "git *\n└── pcre2\ntree *\n" {
+		t.Errorf("Unexpected tree:\n%s", stdout)
+	}
+	if code, _, stderr := run(t, "tree", "firefox"); code != ExitError || !strings.Contains(stderr, "no installed formula named firefox") {
+		t.Errorf("Expected tree to reject casks, got %d %q", code, stderr)
+	}
+	if _, stdout, _ := run(t, "why", "pcre2"); stdout != "pcre2 is needed by:\n  git → pcre2\n" {
+		t.Errorf("Unexpected why output:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "why", "tree"); !strings.Contains(stdout, "tree was installed on request") {
+		t.Errorf("Unexpected why output:\n%s", stdout)
+	}
+	_, stdout, _ := run(t, "graph", "git")
+	if !strings.Contains(stdout, `"git" -> "pcre2";`) || strings.Contains(stdout, "firefox") {
+		t.Errorf("Expected graph of git and its dependencies, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "graph", "--format", "mermaid"); !strings.HasPrefix(stdout, "graph LR\n") {
+		t.Errorf("Expected Mermaid output, got:\n%s", stdout)
+	}
+}
+
+func TestRunExportToFile(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	path := filepath.Join(t.TempDir(), "packages.md")
+	code, stdout, stderr := run(t, "export", "markdown", "-o", path, "git")
+	if code != ExitOK {
+		t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "| git \\* |") || strings.Contains(string(data), "pcre2") {
+		t.Errorf("Unexpected export:\n%s", data)
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+	if code, stdout, _ := run(t, "doctor"); code != ExitOK || !strings.Contains(stdout, "No problems found in 3 formulae and 1 casks.") {
+		t.Errorf("Expected a clean report, got %d:\n%s", code, stdout)
+	}
+
+	info := testBrewInfo()
+	info.Formulae[0].Deprecated = true
+	useBrewInfo(t, info, nil)
+	code, stdout, _ := run(t, "doctor")
+	if code != ExitProblems {
+		t.Errorf("Expected exit %d, got %d", ExitProblems, code)
+	}
+	if stdout != "warning: git: formula is deprecated\n" {
+		t.Errorf("Unexpected doctor output:\n%s", stdout)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	original := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = original })
+
+	tests := []struct {
+		info     *debug.BuildInfo
+		expected string
+	}{
+		{info: &debug.BuildInfo{Main: debug.Module{Version: "v1.4.0"}}, expected: "v1.4.0"},
+		{info: &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, expected: "devel"},
+		{
+			info: &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}, Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "5ed0351a9c1f0e2b3d4c5b6a7f8e9d0c1b2a3f4e"},
+				{Key: "vcs.modified", Value: "true"},
+			}},
+			expected: "devel (5ed0351a9c1f, modified)",
+		},
+	}
+	for _, tt := range tests {
+		readBuildInfo = func() (*debug.BuildInfo, bool) { return tt.info, true }
+		if got := version(); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+
+	Version = "v9.9.9"
+	t.Cleanup(func() { Version = "" })
+	if _, stdout, _ := run(t, "version"); !strings.HasPrefix(stdout, "brewls v9.9.9 (go") {
+		t.Errorf("Unexpected version output %q", stdout)
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "")
+	name := fs.String("name", "", "")
+
+	args, err := parseInterspersed(fs, []string{"a", "-v", "b", "--name", "x", "--", "-c", "d"})
+	if err != nil {
+		t.Fatalf("parseInterspersed returned error: %v", err)
+	}
+	if want := []string{"a", "b", "-c", "d"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Expected %v, got %v", want, args)
+	}
+	if !*verbose || *name != "x" {
+		t.Errorf("Expected flags to be parsed, got v=%v name=%q", *verbose, *name)
+	}
+}
+
+func TestRunFeatureFlags(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv(brewls.LegacyFeatureFlagsEnv, "")
+
+	code, stdout, stderr := run(t, "flags", "--feature", "sort-output", "--feature", "-installed-by-count,typo")
+	if code != ExitOK {
+		t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+	}
+	if !strings.Contains(stderr, `unknown feature flag "typo" (set by --feature)`) {
+		t.Errorf("Expected a warning about typo, got %q", stderr)
+	}
+	for _, want := range []string{"NAME", "sort-output", "on     flag", "installed-by-count"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected flags output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	// --feature only lasts for one run.
+	_, stdout, _ = run(t, "flags", "--json")
+	if !strings.Contains(stdout, `"source": "default"`) || strings.Contains(stdout, `"source": "flag"`) {
+		t.Errorf("Expected defaults after the previous run, got:\n%s", stdout)
+	}
+
+	// sort-output changes the default list order.
+	info := testBrewInfo()
+	info.Formulae[0], info.Formulae[2] = info.Formulae[2], info.Formulae[0]
+	useBrewInfo(t, info, nil)
+	_, stdout, _ = run(t, "--feature", "sort-output", "--format", "tsv", "--columns", "name")
+	if expected := "Name\ngit\npcre2\ntree\nfirefox\n"; stdout != expected {
+		t.Errorf("Expected sorted output:\n%s\nGot:\n%s", expected, stdout)
+	}
+}
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunConfigPrecedence(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+	t.Setenv(brewls.ColumnsEnv, "")
+	t.Setenv(brewls.ConfigEnv, writeConfig(t, `{
+  "format": "tsv",
+  "columns": ["name", "version"],
+  "sort": "-name",
+  "views": {
+    "roots": {"columns": ["name"], "filter": "root", "features": {"sort-output": true}}
+  }
+}`))
+
+	tests := []struct {
+		name     string
+		env      string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "config",
+			expected: "Name\tVersion\ntree\t2.1.1\npcre2\t10.42\ngit\t2.44.0\nfirefox\t125.0\n",
+		},
+		{
+			name:     "env over config",
+			env:      "name",
+			expected: "Name\ntree\npcre2\ngit\nfirefox\n",
+		},
+		{
+			name:     "view over config",
+			args:     []string{"--view", "roots"},
+			expected: "Name\ntree\ngit\nfirefox\n",
+		},
+		{
+			name:     "view over env",
+			env:      "name,version",
+			args:     []string{"--view", "roots"},
+			expected: "Name\ntree\ngit\nfirefox\n",
+		},
+		{
+			name:     "flags over view",
+			args:     []string{"--view", "roots", "--sort", "name", "--format", "csv"},
+			expected: "Name\ngit\ntree\nfirefox\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(brewls.ColumnsEnv, tt.env)
+			code, stdout, stderr := run(t, tt.args...)
+			if code != ExitOK {
+				t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+			}
+			if stdout != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestRunConfigViewTurnsSettingsOff(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+	path := writeConfig(t, `{"summary": true, "wide": true, "views": {"plain": {"summary": false, "wide": false}}}`)
+
+	if _, stdout, _ := run(t, "--config", path); !strings.Contains(stdout, "3 formulae, 1 cask") {
+		t.Errorf("Expected the config to turn the summary on, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "--config", path, "--view", "plain"); strings.Contains(stdout, "3 formulae, 1 cask") {
+		t.Errorf("Expected the view to turn the summary off, got:\n%s", stdout)
+	}
+	_, stdout, _ := run(t, "config", "show", "--config", path, "--view", "plain")
+	if collapsed := strings.Join(strings.Fields(stdout), " "); !strings.Contains(collapsed, `wide "false" view plain`) || !strings.Contains(collapsed, `summary "false" view plain`) {
+		t.Errorf("Expected the view to turn wide and summary off, got:\n%s", stdout)
+	}
+}
+
+func TestRunConfigErrors(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	path := writeConfig(t, `{"sort": "nmae"}`)
+	code, _, stderr := run(t, "--config", path)
+	if code != ExitUsage || !strings.Contains(stderr, `invalid sort (from config): unknown sort key "nmae"`) {
+		t.Errorf("Expected a usage error naming the config, got %d %q", code, stderr)
+	}
+
+	code, _, stderr = run(t, "--config", path, "--view", "audit")
+	if code != ExitUsage || !strings.Contains(stderr, `unknown view "audit" (no views are defined`) {
+		t.Errorf("Expected an unknown view error, got %d %q", code, stderr)
+	}
+
+	code, _, stderr = run(t, "--config", filepath.Join(t.TempDir(), "missing.json"))
+	if code != ExitError || !strings.Contains(stderr, "missing.json") {
+		t.Errorf("Expected an explicit missing config to fail, got %d %q", code, stderr)
+	}
+
+	code, _, stderr = run(t, "--config", writeConfig(t, `{"colums": []}`))
+	if code != ExitError || !strings.Contains(stderr, `unknown field "colums"`) {
+		t.Errorf("Expected an invalid config to fail, got %d %q", code, stderr)
+	}
+}
+
+func TestRunConfigShow(t *testing.T) {
+	t.Setenv(brewls.ColumnsEnv, "name,tap")
+	t.Setenv(brewls.FeatureFlagsEnv, "")
+	t.Setenv(brewls.LegacyFeatureFlagsEnv, "")
+	path := writeConfig(t, `{"format": "csv", "brew_path": "/opt/homebrew/bin/brew", "features": {"sort-output": true}, "views": {"audit": {"filter": "outdated"}}}`)
+	t.Cleanup(func() { brewls.BrewPath = "brew" })
+
+	code, stdout, stderr := run(t, "config", "show", "--config", path, "--view", "audit")
+	if code != ExitOK {
+		t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+	}
+	// Compare with runs of spaces collapsed, as column widths depend on the longest value.
+	collapsed := strings.Join(strings.Fields(strings.ReplaceAll(stdout, "\n", " | ")), " ")
+	for _, want := range []string{
+		"Config file: " + path + " | View: audit |",
+		`format "csv" config`,
+		`columns "name,tap" env BREWLS_COLUMNS`,
+		`sort "name" default`,
+		`filter "outdated" view audit`,
+		`brew_path "/opt/homebrew/bin/brew" config`,
+		`features.sort-output on config`,
+		`views audit config`,
+	} {
+		if !strings.Contains(collapsed, want) {
+			t.Errorf("Expected config show to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	_, stdout, _ = run(t, "config", "show", "--json", "--config", path)
+	var merged brewls.Config
+	if err := json.Unmarshal([]byte(stdout), &merged); err != nil {
+		t.Fatalf("config show --json is not a config file: %v\n%s", err, stdout)
+	}
+	if merged.Format != "csv" || merged.BrewPath != "/opt/homebrew/bin/brew" || !merged.Features["sort-output"] || len(merged.Views) != 1 {
+		t.Errorf("Unexpected merged config %+v", merged)
+	}
+
+	if _, stdout, _ := run(t, "config", "path", "--config", path); stdout != path+"\n" {
+		t.Errorf("Expected config path %q, got %q", path, stdout)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if useColor(colorAuto, &bytes.Buffer{}) || useColor(colorAuto, file) {
+		t.Errorf("Expected auto to disable color for buffers and regular files")
+	}
+	if !useColor(colorAlways, file) || useColor(colorNever, file) {
+		t.Errorf("Expected always and never to be honored")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if !useColor(colorAlways, &bytes.Buffer{}) {
+		t.Errorf("Expected --color=always to override NO_COLOR")
+	}
+}
+
+func TestRunColorAndStyle(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	if _, stdout, _ := run(t, "--columns", "name"); strings.Contains(stdout, "\x1b[") {
+		t.Errorf("Expected no color when piped, got %q", stdout)
+	}
+	if _, stdout, _ := run(t, "--columns", "name", "--color", "always", "--style", "rounded"); !strings.Contains(stdout, "╭") || !strings.Contains(stdout, "\x1b[1;32mgit *\x1b[0m") {
+		t.Errorf("Expected a colored rounded table, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "export", "csv", "--config", writeConfig(t, `{"color": "always"}`)); strings.Contains(stdout, "\x1b[") {
+		t.Errorf("Expected export to ignore color, got %q", stdout)
+	}
+
+	if code, _, stderr := run(t, "--style", "fancy"); code != ExitUsage || !strings.Contains(stderr, `unknown table style "fancy"`) {
+		t.Errorf("Expected a style usage error, got %d %q", code, stderr)
+	}
+	if code, _, stderr := run(t, "--config", writeConfig(t, `{"color": "sometimes"}`)); code != ExitUsage || !strings.Contains(stderr, `invalid color mode "sometimes" (from config)`) {
+		t.Errorf("Expected a color usage error, got %d %q", code, stderr)
+	}
+}
+
+func TestRunColumnWidths(t *testing.T) {
+	info := testBrewInfo()
+	info.Formulae[2].Installed[0].RuntimeDependencies = []brewls.RuntimeDependency{{FullName: "pcre2"}}
+	brewls.BuildReverseDependencyGraph(info)
+	useBrewInfo(t, info, nil)
+
+	path := writeConfig(t, `{"widths": {"installed_by": 40}, "views": {"narrow": {"widths": {"installed_by": 3}}}}`)
+	if _, stdout, _ := run(t, "--config", path, "pcre2"); !strings.Contains(stdout, "| git, tree    |") {
+		t.Errorf("Expected the full list, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "--config", path, "--view", "narrow", "pcre2"); !strings.Contains(stdout, "| git +1 more  |") {
+		t.Errorf("Expected the view's width to truncate the list, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "export", "csv", "--config", path, "--view", "narrow", "pcre2"); !strings.Contains(stdout, `"git, tree"`) {
+		t.Errorf("Expected export to ignore widths, got:\n%s", stdout)
+	}
+	_, stdout, _ := run(t, "config", "show", "--config", path, "--view", "narrow")
+	if collapsed := strings.Join(strings.Fields(stdout), " "); !strings.Contains(collapsed, "widths.installed_by 3 view narrow") || !strings.Contains(collapsed, `wide "false" default`) {
+		t.Errorf("Expected widths and wide in config show, got:\n%s", stdout)
+	}
+
+	if code, _, stderr := run(t, "--config", writeConfig(t, `{"widths": {"installed": 10}}`)); code != ExitUsage || !strings.Contains(stderr, `unknown column "installed" in widths (from config)`) {
+		t.Errorf("Expected an unknown column usage error, got %d %q", code, stderr)
+	}
+	if code, _, stderr := run(t, "--config", writeConfig(t, `{"widths": {"desc": -1}}`)); code != ExitUsage || !strings.Contains(stderr, `invalid width -1 for column "desc"`) {
+		t.Errorf("Expected a negative width usage error, got %d %q", code, stderr)
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	if width := terminalWidth(&bytes.Buffer{}); width != 0 {
+		t.Errorf("Expected no width for output that is not a terminal, got %d", width)
+	}
+}
+
+func TestRunNamesOnly(t *testing.T) {
+	// Selections restrict the loaded info in place, so every run gets a fresh copy.
+	original := loadBrewInfo
+	loadBrewInfo = func() (*brewls.BrewInfo, error) {
+		info := testBrewInfo()
+		info.Formulae = append(info.Formulae, brewls.Formula{Name: "oldlib", Installed: []brewls.Installed{{Version: "1.0"}}})
+		brewls.BuildReverseDependencyGraph(info)
+		return info, nil
+	}
+	t.Cleanup(func() { loadBrewInfo = original })
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-1"}, "git\npcre2\ntree\noldlib\nfirefox\n"},
+		{[]string{"--names-only", "--type", "cask"}, "firefox\n"},
+		{[]string{"--roots-only"}, "git\ntree\nfirefox\n"},
+		{[]string{"--roots-only", "--type", "formula", "-0"}, "git\x00tree\x00"},
+		{[]string{"--orphans", "-1"}, "oldlib\n"},
+		{[]string{"-1", "--filter", "!root", "--sort", "-name"}, "pcre2\noldlib\n"},
+		{[]string{"--config", writeConfig(t, `{"format": "csv"}`), "-1", "g*"}, "git\n"},
+	}
+	for _, tt := range tests {
+		code, stdout, stderr := run(t, tt.args...)
+		if code != ExitOK || stdout != tt.want {
+			t.Errorf("%q: expected %q, got %d %q %s", tt.args, tt.want, code, stdout, stderr)
+		}
+	}
+
+	if _, stdout, _ := run(t, "--orphans", "--columns", "name"); !strings.Contains(stdout, "oldlib") || strings.Contains(stdout, "pcre2") {
+		t.Errorf("Expected --orphans to select oldlib in a table, got:\n%s", stdout)
+	}
+	if code, _, stderr := run(t, "--type", "tap"); code != ExitUsage || !strings.Contains(stderr, `invalid type "tap"`) {
+		t.Errorf("Expected a type usage error, got %d %q", code, stderr)
+	}
+	if code, _, stderr := run(t, "-1", "--format", "json"); code != ExitUsage || !strings.Contains(stderr, "cannot be combined") {
+		t.Errorf("Expected a conflict usage error, got %d %q", code, stderr)
+	}
+}
+
+func TestRunTemplate(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	code, stdout, stderr := run(t, "--template", `{{.Name}} {{join .InstalledBy ","}}`, "--type", "formula")
+	if want := "git \npcre2 git\ntree \n"; code != ExitOK || stdout != want {
+		t.Errorf("Expected %q, got %d %q %s", want, code, stdout, stderr)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte("{{.Name}}: {{deps .Name}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Graph helpers see the whole installation even when the listing is narrowed.
+	if _, stdout, _ := run(t, "--template-file", path, "git"); stdout != "git: [pcre2]\n" {
+		t.Errorf("Expected the template file to be used, got %q", stdout)
+	}
+
+	if code, _, stderr := run(t, "--template", "{{.Name"); code != ExitUsage || !strings.Contains(stderr, "unclosed action at line 1, column 1") {
+		t.Errorf("Expected a template usage error, got %d %q", code, stderr)
+	}
+	if code, _, stderr := run(t, "--template", "{{.Nope}}"); code != ExitError || !strings.Contains(stderr, "can't evaluate field Nope") {
+		t.Errorf("Expected a template execution error, got %d %q", code, stderr)
+	}
+	if code, _, stderr := run(t, "--template", "{{.Name}}", "--format", "json"); code != ExitUsage || !strings.Contains(stderr, "cannot be combined") {
+		t.Errorf("Expected a conflict usage error, got %d %q", code, stderr)
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	code, stdout, stderr := run(t, "stats")
+	collapsed := strings.Join(strings.Fields(stdout), " ")
+	if want := "Formulae: 3 Casks: 1 Roots: 3 Dependencies: 1 Orphans: 0 Outdated: 0 Average depth: 0.5 Max depth: 1 Most depended on: pcre2 1"; code != ExitOK || collapsed != want {
+		t.Errorf("Expected %q, got %d %q %s", want, code, collapsed, stderr)
+	}
+
+	_, stdout, _ = run(t, "stats", "--json", "--top", "0")
+	var stats brewls.Stats
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("stats --json is not JSON: %v\n%s", err, stdout)
+	}
+	if stats.Formulae != 3 || stats.Roots != 3 || len(stats.MostDependedOn) != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if _, stdout, _ := run(t, "--summary", "--type", "formula"); !strings.HasSuffix(stdout, "\n3 formulae, 0 casks: 2 roots, 1 dependency, 0 orphans, 0 outdated\n") {
+		t.Errorf("Expected a summary footer, got:\n%s", stdout)
+	}
+	if _, stdout, _ := run(t, "--config", writeConfig(t, `{"summary": true}`)); !strings.Contains(stdout, "3 formulae, 1 cask:") {
+		t.Errorf("Expected the config to turn on the summary, got:\n%s", stdout)
+	}
+}
+
+func TestRunExportBrewfile(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	if code, _, stderr := run(t, "export", "brewfile", "--comments", "-o", path); code != ExitOK {
+		t.Fatalf("Expected exit %d, got %d: %s", ExitOK, code, stderr)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Brewfile written by brewls export brewfile; install with brew bundle.\nbrew \"git\" # pulls in pcre2\nbrew \"tree\"\ncask \"firefox\"\n"
+	if string(data) != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, data)
+	}
+
+	if _, stdout, _ := run(t, "export", "brewfile", "--type", "cask"); !strings.HasSuffix(stdout, "\ncask \"firefox\"\n") || strings.Contains(stdout, "brew \"") {
+		t.Errorf("Expected only the cask, got:\n%s", stdout)
+	}
+}
+
+func TestRunExportCycloneDX(t *testing.T) {
+	originalNow, originalHostname := now, hostname
+	now = func() time.Time { return time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC) }
+	hostname = func() string { return "laptop" }
+	t.Cleanup(func() { now, hostname = originalNow, originalHostname })
+	useBrewInfo(t, testBrewInfo(), nil)
+
+	code, stdout, stderr := run(t, "export", "cyclonedx")
+	var doc brewls.CycloneDXDocument
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil || code != ExitOK {
+		t.Fatalf("Expected a CycloneDX document, got %d (%v): %s", code, err, stderr)
+	}
+	if doc.SpecVersion != "1.5" || len(doc.Components) != 4 || doc.Metadata.Timestamp != "2026-10-01T09:30:00Z" || doc.Metadata.Component.Name != "laptop" {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	if doc.Dependencies[0].Ref != "pkg:brew/git@2.44.0" || !reflect.DeepEqual(doc.Dependencies[0].DependsOn, []string{"pkg:brew/pcre2@10.42"}) {
+		t.Errorf("Expected git to depend on pcre2, got %+v", doc.Dependencies[0])
+	}
+}
+
+func TestRunExportSPDX(t *testing.T) {
+	originalNow := now
+	t.Cleanup(func() { now = originalNow })
+	info := testBrewInfo()
+	info.Formulae[0].Installed[0].Time = time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC).Unix()
+	info.Formulae[2].Installed[0].Time = time.Date(2026, 8, 1, 8, 0, 0, 0, time.UTC).Unix()
+	useBrewInfo(t, info, nil)
+
+	// Without SOURCE_DATE_EPOCH the latest install time is the creation time,
+	// so exports at different times are identical.
+	now = func() time.Time { return time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC) }
+	code, first, stderr := run(t, "export", "spdx")
+	var doc brewls.SPDXDocument
+	if err := json.Unmarshal([]byte(first), &doc); err != nil || code != ExitOK {
+		t.Fatalf("Expected an SPDX document, got %d (%v): %s", code, err, stderr)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || len(doc.Packages) != 4 || doc.CreationInfo.Created != "2026-09-01T08:00:00Z" {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	now = func() time.Time { return time.Date(2026, 10, 2, 11, 0, 0, 0, time.UTC) }
+	if _, second, _ := run(t, "export", "spdx"); second != first {
+		t.Errorf("Expected repeated exports to be identical:\n%s\n%s", first, second)
+	}
+
+	t.Setenv(brewls.SourceDateEpochEnv, "1790000000")
+	_, stdout, _ := run(t, "export", "spdx")
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil || doc.CreationInfo.Created != "2026-09-21T14:13:20Z" {
+		t.Errorf("Expected the time from %s, got %q (%v)", brewls.SourceDateEpochEnv, doc.CreationInfo.Created, err)
+	}
+
+	// An invalid value is ignored with a warning, and formats that record no
+	// time do not look at it.
+	t.Setenv(brewls.SourceDateEpochEnv, "yesterday")
+	code, stdout, stderr = run(t, "export", "spdx")
+	if code != ExitOK || stdout != first || !strings.Contains(stderr, "warning: invalid SOURCE_DATE_EPOCH") {
+		t.Errorf("Expected a warning and the default time, got %d %q", code, stderr)
+	}
+	for _, args := range [][]string{{}, {"--format", "csv"}} {
+		if code, _, stderr := run(t, args...); code != ExitOK || stderr != "" {
+			t.Errorf("Expected %v to ignore %s, got %d %q", args, brewls.SourceDateEpochEnv, code, stderr)
+		}
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+	dir := t.TempDir()
+	brewfile := func(data string) string {
+		path := filepath.Join(dir, "Brewfile")
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := brewfile("cask_args appdir: \"~/Applications\"\nbrew \"git\"\nbrew \"tree\"\ncask \"firefox\"\nmas \"Xcode\", id: 497799835\n")
+	if code, stdout, stderr := run(t, "check", "--brewfile", path); code != ExitOK || !strings.Contains(stdout, "is in sync") ||
+		stderr != "brewls check: note: line 5: skipped mas \"Xcode\", id: 497799835; only brew and cask entries are checked\n" {
+		t.Errorf("Expected an in-sync Brewfile with a note on mas, got %d %q %q", code, stdout, stderr)
+	}
+
+	path = brewfile("brew \"git\"\nbrew \"pcre2\"\nbrew \"htop\"\n")
+	code, stdout, stderr := run(t, "check", "--brewfile", path)
+	if code != ExitProblems || !strings.Contains(stderr, "1 missing, 2 extra, 1 only installed as dependencies") {
+		t.Errorf("Expected drift, got %d %q", code, stderr)
+	}
+	for _, want := range []string{`- brew "htop"`, `+ brew "tree"`, `+ cask "firefox"`, `~ brew "pcre2"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in:\n%s", want, stdout)
+		}
+	}
+
+	code, stdout, _ = run(t, "check", "--json", "--brewfile", path)
+	var report struct {
+		InSync  bool                   `json:"in_sync"`
+		Missing []brewls.BrewfileEntry `json:"missing"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || code != ExitProblems || report.InSync || len(report.Missing) != 1 {
+		t.Errorf("Unexpected JSON report %d %+v (%v):\n%s", code, report, err, stdout)
+	}
+
+	if code, _, stderr := run(t, "check", "--brewfile", brewfile("brew git\n")); code != ExitError || !strings.Contains(stderr, "line 1:") {
+		t.Errorf("Expected a parse error, got %d %q", code, stderr)
+	}
+}
+
+func TestRunLicenses(t *testing.T) {
+	info := testBrewInfo()
+	info.Formulae[0].License = "GPL-2.0-only"
+	info.Formulae[1].License = "BSD-3-Clause"
+	info.Formulae[2].License = "GPL-2.0-or-later"
+	useBrewInfo(t, info, nil)
+
+	code, stdout, stderr := run(t, "licenses")
+	if code != ExitOK || !strings.Contains(stdout, "  git: git (GPL-2.0-only, strong)\n") || strings.Contains(stdout, "policy") {
+		t.Errorf("Expected a report without a policy, got %d %s%s", code, stdout, stderr)
+	}
+
+	t.Setenv(brewls.ConfigEnv, writeConfig(t, `{"licenses": {"deny": ["GPL-2.0-only"]}}`))
+	code, stdout, stderr = run(t, "licenses")
+	if code != ExitProblems || !strings.Contains(stdout, "  formula git (GPL-2.0-only): denied: GPL-2.0-only\n") || !strings.Contains(stderr, "1 package(s) violate") {
+		t.Errorf("Expected a denied git, got %d %s%s", code, stdout, stderr)
+	}
+	if _, stdout, _ := run(t, "config", "show"); !strings.Contains(strings.Join(strings.Fields(stdout), " "), `licenses.deny "GPL-2.0-only" config`) {
+		t.Errorf("Expected the deny list in config show:\n%s", stdout)
+	}
+
+	// Flags replace the lists from the config file.
+	code, stdout, _ = run(t, "licenses", "--json", "--deny", "AGPL-*", "--allow", "GPL-*,BSD-*")
+	var report brewls.LicenseReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || code != ExitProblems {
+		t.Fatalf("Unexpected JSON report %d (%v):\n%s", code, err, stdout)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Name != "firefox" || report.Violations[0].Reason != "no license known" {
+		t.Errorf("Expected only the cask without a license to violate the allow list, got %+v", report.Violations)
+	}
+
+	if code, _, stderr := run(t, "licenses", "--deny", "GPL-["); code != ExitUsage || !strings.Contains(stderr, "invalid license pattern") {
+		t.Errorf("Expected a usage error, got %d %q", code, stderr)
+	}
+}
+
+func TestRunSnapshotAndDiff(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	originalVersion, originalNow := brewVersion, now
+	brewVersion = func() (string, error) { return "Homebrew 4.3.1", nil }
+	now = func() time.Time { return time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC) }
+	t.Cleanup(func() { brewVersion, now = originalVersion, originalNow })
+
+	useBrewInfo(t, testBrewInfo(), nil)
+	code, stdout, stderr := run(t, "snapshot", "save")
+	if code != ExitOK || !strings.Contains(stdout, "Saved snapshot 20261001-093000 with 4 packages") {
+		t.Fatalf("Expected a saved snapshot, got %d %q %s", code, stdout, stderr)
+	}
+	if code, _, stderr := run(t, "snapshot", "save", "20261001-093000"); code != ExitError || !strings.Contains(stderr, "snapshot already exists") {
+		t.Errorf("Expected an existing snapshot error, got %d %q", code, stderr)
+	}
+
+	// A bad name fails before brew runs.
+	useBrewInfo(t, nil, errors.New("brew should not run"))
+	if code, _, stderr := run(t, "snapshot", "save", "../escape"); code != ExitUsage || !strings.Contains(stderr, "invalid snapshot name") {
+		t.Errorf("Expected a usage error without running brew, got %d %q", code, stderr)
+	}
+
+	info := testBrewInfo()
+	info.Formulae[0].Installed[0].Version = "2.45.0"
+	info.Formulae = info.Formulae[:2] // tree uninstalled
+	brewls.BuildReverseDependencyGraph(info)
+	useBrewInfo(t, info, nil)
+	if code, _, stderr := run(t, "snapshot", "save", "after"); code != ExitOK {
+		t.Fatalf("Expected a saved snapshot, got %d %s", code, stderr)
+	}
+
+	if _, stdout, _ := run(t, "snapshot", "list"); stdout != "20261001-093000\nafter\n" {
+		t.Errorf("Expected both snapshots, got %q", stdout)
+	}
+
+	code, stdout, stderr = run(t, "diff", "20261001-093000", "after")
+	want := "~ formula git 2.44.0 -> 2.45.0"
+	if code != ExitOK || !strings.Contains(stdout, "- formula tree 2.1.1") || !strings.Contains(stdout, want) {
+		t.Errorf("Expected the changes, got %d %q %s", code, stdout, stderr)
+	}
+
+	// A snapshot file from another machine compares with the installed state.
+	dir, _ := brewls.SnapshotDir()
+	copied := filepath.Join(t.TempDir(), "other.json")
+	data, _ := os.ReadFile(filepath.Join(dir, "after.json"))
+	os.WriteFile(copied, data, 0o644)
+	if _, stdout, _ := run(t, "diff", copied); !strings.Contains(stdout, "No changes.") {
+		t.Errorf("Expected no changes against the installed state, got %q", stdout)
+	}
+
+	_, stdout, _ = run(t, "diff", "--json", "20261001-093000", "after")
+	var diff brewls.SnapshotDiff
+	if err := json.Unmarshal([]byte(stdout), &diff); err != nil || len(diff.Removed) != 1 || len(diff.VersionChanges) != 1 {
+		t.Errorf("Unexpected JSON diff %+v (%v):\n%s", diff, err, stdout)
+	}
+
+	if code, _, stderr := run(t, "diff", "nope"); code != ExitUsage || !strings.Contains(stderr, `no snapshot named "nope"`) {
+		t.Errorf("Expected a missing snapshot error, got %d %q", code, stderr)
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	originalNow := now
+	now = func() time.Time { return time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = originalNow })
+
+	if _, stdout, _ := run(t, "history"); !strings.Contains(stdout, "No history recorded yet; run brewls history record") {
+		t.Errorf("Expected an empty history, got %q", stdout)
+	}
+
+	useBrewInfo(t, testBrewInfo(), nil)
+	if code, stdout, stderr := run(t, "history", "record"); code != ExitOK || stdout != "Recorded 4 change(s).\n" {
+		t.Fatalf("Expected 4 recorded changes, got %d %q %s", code, stdout, stderr)
+	}
+
+	// Commands that run brew record changes on a terminal unless the feature
+	// is off, and elsewhere only when it is turned on.
+	info := testBrewInfo()
+	info.Formulae[0].Installed[0].Version = "2.45.0"
+	brewls.BuildReverseDependencyGraph(info)
+	useBrewInfo(t, info, nil)
+	originalInteractive := interactive
+	t.Cleanup(func() { interactive = originalInteractive })
+	for _, tt := range []struct {
+		terminal bool
+		args     []string
+	}{
+		{false, []string{"list"}},
+		{true, []string{"list", "--feature", "-record-history"}},
+	} {
+		interactive = func(io.Writer) bool { return tt.terminal }
+		if code, _, stderr := run(t, tt.args...); code != ExitOK || stderr != "" {
+			t.Fatalf("Expected %v to succeed, got %d %s", tt.args, code, stderr)
+		}
+		if _, stdout, _ := run(t, "history", "git"); strings.Contains(stdout, "upgraded") {
+			t.Errorf("Expected nothing recorded by %v (terminal %v), got %q", tt.args, tt.terminal, stdout)
+		}
+	}
+	interactive = func(io.Writer) bool { return true }
+	if code, _, stderr := run(t, "list"); code != ExitOK || stderr != "" {
+		t.Fatalf("Expected list to succeed, got %d %s", code, stderr)
+	}
+
+	_, stdout, _ := run(t, "history", "git")
+	want := "upgraded     formula git 2.44.0 → 2.45.0"
+	if strings.Count(stdout, "\n") != 2 || !strings.Contains(stdout, want) {
+		t.Errorf("Expected git's install and upgrade, got %q", stdout)
+	}
+
+	_, stdout, _ = run(t, "history", "--json")
+	var events []brewls.HistoryEvent
+	if err := json.Unmarshal([]byte(stdout), &events); err != nil || len(events) != 5 {
+		t.Errorf("Expected 5 JSON events, got %+v (%v)", events, err)
+	}
+
+	if code, _, _ := run(t, "history", "a", "b"); code != ExitUsage {
+		t.Errorf("Expected a usage error, got %d", code)
+	}
+}
+
+func TestRunUpgrades(t *testing.T) {
+	useBrewInfo(t, testBrewInfo(), nil)
+	if _, stdout, _ := run(t, "upgrades"); stdout != "Everything is up to date.\n" {
+		t.Errorf("Expected no upgrades, got %q", stdout)
+	}
+
+	info := testBrewInfo()
+	info.Formulae[1].Outdated = true
+	info.Formulae[1].Versions.Stable = "11.0"
+	useBrewInfo(t, info, nil)
+	code, stdout, stderr := run(t, "upgrades")
+	if code != ExitOK || !strings.Contains(stdout, "! pcre2 10.42 → 11.0\n    affects git\n") {
+		t.Errorf("Expected a major upgrade affecting git, got %d %q %s", code, stdout, stderr)
+	}
+
+	// Between a snapshot and the installed state.
+	useBrewInfo(t, testBrewInfo(), nil)
+	if code, _, stderr := run(t, "snapshot", "save", "before"); code != ExitOK {
+		t.Fatalf("Expected a saved snapshot, got %d %s", code, stderr)
+	}
+	info = testBrewInfo()
+	info.Formulae[0].Installed[0].Version = "2.44.0_1"
+	useBrewInfo(t, info, nil)
+	_, stdout, _ = run(t, "upgrades", "--json", "before")
+	var upgrades []brewls.Upgrade
+	if err := json.Unmarshal([]byte(stdout), &upgrades); err != nil || len(upgrades) != 1 || upgrades[0].Bump != "revision" {
+		t.Errorf("Expected one revision upgrade, got %+v (%v)", upgrades, err)
+	}
+}
@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"brewls/internal/brewls"
+)
+
+// loadConfig reads the config file named by --config, or the default one.
+// Only an explicitly named file has to exist.
+func (env *Env) loadConfig(path, view string) error {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = brewls.DefaultConfigPath(); err != nil {
+			return err
+		}
+	}
+
+	config, err := brewls.LoadConfig(path)
+	switch {
+	case err == nil:
+		env.ConfigFound = true
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		config = &brewls.Config{}
+	default:
+		return err
+	}
+	env.Config, env.ConfigPath = config, path
+
+	env.ViewName, env.View = view, nil
+	if view != "" {
+		selected, err := config.View(view)
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		env.View = &selected
+	}
+	return nil
+}
+
+// Sources a listing setting can come from, besides "view NAME".
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+// setting is a resolved listing setting and where its value came from.
+type setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// settingKeys are the listing settings that the config file, views, env vars
+// and flags can all set, with the flag that sets each.
+var settingKeys = []struct{ key, flag, env string }{
+	{key: "format", flag: "format"},
+	{key: "columns", flag: "columns", env: brewls.ColumnsEnv},
+	{key: "sort", flag: "sort"},
+	{key: "filter", flag: "filter"},
+	{key: "list_separator", flag: "list-separator"},
+	{key: "color", flag: "color"},
+	{key: "style", flag: "style"},
+	{key: "wide", flag: "wide"},
+	{key: "summary", flag: "summary"},
+}
+
+// resolve returns the value for a listing setting, taking the first of: a flag
+// set on fs (which may be nil), the selected view, its env var, the top level
+// of the config file, and the built-in default.
+func (env *Env) resolve(key string, fs *flag.FlagSet) setting {
+	for _, k := range settingKeys {
+		if k.key != key {
+			continue
+		}
+		if fs != nil {
+			var value string
+			set := false
+			fs.Visit(func(f *flag.Flag) {
+				if f.Name == k.flag {
+					value, set = f.Value.String(), true
+				}
+			})
+			if set {
+				return setting{key, value, sourceFlag}
+			}
+		}
+		// A view is selected on the command line, so it outranks env vars.
+		if env.View != nil {
+			if value := configValue(*env.View, key); value != "" {
+				return setting{key, value, "view " + env.ViewName}
+			}
+		}
+		if k.env != "" {
+			if value := strings.TrimSpace(os.Getenv(k.env)); value != "" {
+				return setting{key, value, sourceEnv + " " + k.env}
+			}
+		}
+		if value := configValue(env.Config.ConfigView, key); value != "" {
+			return setting{key, value, sourceConfig}
+		}
+		return setting{key, defaultValue(key), sourceDefault}
+	}
+	panic("brewls: unknown setting " + key)
+}
+
+func configValue(view brewls.ConfigView, key string) string {
+	switch key {
+	case "format":
+		return view.Format
+	case "columns":
+		return strings.Join(view.Columns, ",")
+	case "sort":
+		return view.Sort
+	case "filter":
+		return view.Filter
+	case "list_separator":
+		return view.ListSeparator
+	case "color":
+		return view.Color
+	case "style":
+		return view.Style
+	case "wide":
+		if view.Wide != nil {
+			return strconv.FormatBool(*view.Wide)
+		}
+	case "summary":
+		if view.Summary != nil {
+			return strconv.FormatBool(*view.Summary)
+		}
+	}
+	return ""
+}
+
+func defaultValue(key string) string {
+	switch key {
+	case "format":
+		return brewls.FormatTable
+	case "columns":
+		return strings.Join(brewls.DefaultColumns(), ",")
+	case "sort":
+		keys := brewls.DefaultViewOptions().Sort
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k.String()
+		}
+		return strings.Join(parts, ",")
+	case "list_separator":
+		return brewls.DefaultListSeparator
+	case "color":
+		return colorAuto
+	case "style":
+		return brewls.TableStyleDefault
+	case "wide", "summary":
+		return "false"
+	}
+	return ""
+}
+
+// widths merges the column width limits of the config file with those of the
+// selected view, which take precedence. Each limit is returned with its source.
+func (env *Env) widths() map[string]setting {
+	widths := make(map[string]setting)
+	for key, width := range env.Config.Widths {
+		widths[key] = setting{key, strconv.Itoa(width), sourceConfig}
+	}
+	if env.View != nil {
+		for key, width := range env.View.Widths {
+			widths[key] = setting{key, strconv.Itoa(width), "view " + env.ViewName}
+		}
+	}
+	return widths
+}
+
+var configCommand = &Command{
+	Name:    "config",
+	Args:    "show|path",
+	Summary: "Show the effective configuration",
+	Help: `'brewls config show' prints every setting with the value brewls will use and
+where it comes from; --json prints the merged result as a config file.
+'brewls config path' prints the config file location.
+
+Settings are taken from flags, then the view selected with --view, then env
+vars, then the top level of the config file, then built-in defaults.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		asJSON := fs.Bool("json", false, "print the merged configuration as JSON")
+		return func(env *Env, args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("config needs one of: show, path")
+			}
+			switch args[0] {
+			case "path":
+				fmt.Fprintln(env.Stdout, env.ConfigPath)
+				return nil
+			case "show":
+				if *asJSON {
+					return writeConfigJSON(env)
+				}
+				return writeConfigTable(env)
+			default:
+				return usageErrorf("unknown config command %q (expected show or path)", args[0])
+			}
+		}
+	},
+}
+
+func writeConfigTable(env *Env) error {
+	status := ""
+	if !env.ConfigFound {
+		status = " (not found)"
+	}
+	fmt.Fprintf(env.Stdout, "Config file: %s%s\n", env.ConfigPath, status)
+	if env.View != nil {
+		fmt.Fprintf(env.Stdout, "View: %s\n", env.ViewName)
+	}
+	fmt.Fprintln(env.Stdout)
+
+	w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, k := range settingKeys {
+		s := env.resolve(k.key, nil)
+		fmt.Fprintf(w, "%s\t%q\t%s\n", s.Key, s.Value, s.Source)
+	}
+
+	widths := env.widths()
+	for _, key := range slices.Sorted(maps.Keys(widths)) {
+		fmt.Fprintf(w, "widths.%s\t%s\t%s\n", key, widths[key].Value, widths[key].Source)
+	}
+
+	brewPath, source := "brew", sourceDefault
+	if env.Config.BrewPath != "" {
+		brewPath, source = env.Config.BrewPath, sourceConfig
+	}
+	fmt.Fprintf(w, "brew_path\t%q\t%s\n", brewPath, source)
+	if len(env.Config.Prefixes) > 0 {
+		fmt.Fprintf(w, "prefixes\t%q\t%s\n", strings.Join(env.Config.Prefixes, ","), sourceConfig)
+	} else {
+		fmt.Fprintf(w, "prefixes\t%q\t%s\n", "(brew --prefix)", sourceDefault)
+	}
+	if allow := env.Config.Licenses.Allow; len(allow) > 0 {
+		fmt.Fprintf(w, "licenses.allow\t%q\t%s\n", strings.Join(allow, ","), sourceConfig)
+	}
+	if deny := env.Config.Licenses.Deny; len(deny) > 0 {
+		fmt.Fprintf(w, "licenses.deny\t%q\t%s\n", strings.Join(deny, ","), sourceConfig)
+	}
+	for _, state := range brewls.FeatureStates() {
+		source := state.Source
+		if source == brewls.FeatureSourceView {
+			source = "view " + env.ViewName
+		}
+		fmt.Fprintf(w, "features.%s\t%s\t%s\n", state.Name, onOff(state.Enabled), source)
+	}
+	if names := env.Config.ViewNames(); len(names) > 0 {
+		fmt.Fprintf(w, "views\t%s\t%s\n", strings.Join(names, ", "), sourceConfig)
+	}
+	return w.Flush()
+}
+
+func writeConfigJSON(env *Env) error {
+	wide := env.resolve("wide", nil).Value == "true"
+	summary := env.resolve("summary", nil).Value == "true"
+	merged := brewls.Config{
+		ConfigView: brewls.ConfigView{
+			Format:        env.resolve("format", nil).Value,
+			Columns:       brewls.ParseColumnList(env.resolve("columns", nil).Value),
+			Sort:          env.resolve("sort", nil).Value,
+			Filter:        env.resolve("filter", nil).Value,
+			ListSeparator: env.resolve("list_separator", nil).Value,
+			Color:         env.resolve("color", nil).Value,
+			Style:         env.resolve("style", nil).Value,
+			Wide:          &wide,
+			Summary:       &summary,
+			Widths:        make(map[string]int),
+			Features:      make(map[string]bool),
+		},
+		BrewPath: brewls.BrewPath,
+		Prefixes: env.Config.Prefixes,
+		Licenses: env.Config.Licenses,
+		Views:    env.Config.Views,
+	}
+	for key, width := range env.widths() {
+		merged.Widths[key], _ = strconv.Atoi(width.Value)
+	}
+	for _, state := range brewls.FeatureStates() {
+		merged.Features[state.Name] = state.Enabled
+	}
+
+	encoder := json.NewEncoder(env.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(merged)
+}
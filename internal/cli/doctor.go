@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"brewls/internal/brewls"
+)
+
+var doctorCommand = &Command{
+	Name:    "doctor",
+	Summary: "Check installed packages for problems",
+	Help: `Check that brew runs and report missing or mismatched runtime dependencies,
+deprecated or disabled packages and dependencies nothing needs any more.
+Exits with status 3 when a warning is found.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		quiet := fs.Bool("quiet", false, "only print warnings, not informational findings")
+		return func(env *Env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("doctor takes no arguments")
+			}
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+
+			warnings := 0
+			for _, d := range brewls.Diagnose(info) {
+				if d.Severity == brewls.SeverityWarning {
+					warnings++
+				} else if *quiet {
+					continue
+				}
+				fmt.Fprintln(env.Stdout, d)
+			}
+			if warnings > 0 {
+				fmt.Fprintf(env.Stderr, "brewls doctor: %d warning(s) in %d formulae and %d casks\n", warnings, len(info.Formulae), len(info.Casks))
+				return exitStatus(ExitProblems)
+			}
+			if !*quiet {
+				fmt.Fprintf(env.Stdout, "No problems found in %d formulae and %d casks.\n", len(info.Formulae), len(info.Casks))
+			}
+			return nil
+		}
+	},
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+
+	"brewls/internal/brewls"
+)
+
+var flagsCommand = &Command{
+	Name:    "flags",
+	Summary: "List feature flags and their current state",
+	Help: `List every registered feature flag with its stage, default and current state,
+and where that state comes from. Flags are set with --feature, the view
+selected with --view, the ` + brewls.FeatureFlagsEnv + ` (or legacy ` + brewls.LegacyFeatureFlagsEnv + `) env var,
+or the config file, in that order of precedence.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		asJSON := fs.Bool("json", false, "print the flags as JSON")
+		return func(env *Env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("flags takes no arguments")
+			}
+			states := brewls.FeatureStates()
+
+			if *asJSON {
+				type jsonFlag struct {
+					Name        string `json:"name"`
+					Description string `json:"description"`
+					Stage       string `json:"stage"`
+					Default     bool   `json:"default"`
+					Enabled     bool   `json:"enabled"`
+					Source      string `json:"source"`
+				}
+				flags := make([]jsonFlag, 0, len(states))
+				for _, s := range states {
+					flags = append(flags, jsonFlag{s.Name, s.Description, string(s.Stage), s.Default, s.Enabled, s.Source})
+				}
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(flags)
+			}
+
+			w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSTAGE\tDEFAULT\tSTATE\tSOURCE\tDESCRIPTION")
+			for _, s := range states {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, s.Stage, onOff(s.Default), onOff(s.Enabled), s.Source, s.Description)
+			}
+			return w.Flush()
+		}
+	},
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"brewls/internal/brewls"
+)
+
+var treeCommand = &Command{
+	Name:    "tree",
+	Args:    "[formula ...]",
+	Summary: "Show the dependency tree of installed formulae",
+	Help: `Print the installed dependencies of each named formula as a tree. Without
+arguments, every formula that no other installed package depends on is shown.
+Root packages are marked with *.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		depth := fs.Int("depth", 0, "only print this many levels of dependencies (0 for all)")
+		return func(env *Env, args []string) error {
+			if *depth < 0 {
+				return usageErrorf("--depth must not be negative")
+			}
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(args))
+			for _, arg := range args {
+				name, ok := findFormula(info, arg)
+				if !ok {
+					return fmt.Errorf("no installed formula named %s", arg)
+				}
+				names = append(names, name)
+			}
+			return brewls.WriteDependencyTree(env.Stdout, info, names, *depth)
+		}
+	},
+}
+
+var whyCommand = &Command{
+	Name:    "why",
+	Args:    "<name>",
+	Summary: "Explain why a package is installed",
+	Help: `Print every chain of installed packages that leads to the named formula or
+cask, starting from a package nothing else depends on.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		return func(env *Env, args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("why needs exactly one package name")
+			}
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+
+			name, onRequest, ok := findPackage(info, args[0])
+			if !ok {
+				return fmt.Errorf("no installed formula or cask named %s", args[0])
+			}
+			paths := brewls.DependencyPaths(info, name)
+			if len(paths) == 1 && len(paths[0]) == 1 {
+				if onRequest {
+					fmt.Fprintf(env.Stdout, "%s was installed on request and no installed package depends on it.\n", name)
+				} else {
+					fmt.Fprintf(env.Stdout, "%s was installed as a dependency but no installed package needs it any more.\n", name)
+				}
+				return nil
+			}
+
+			if onRequest {
+				fmt.Fprintf(env.Stdout, "%s was installed on request and is also needed by:\n", name)
+			} else {
+				fmt.Fprintf(env.Stdout, "%s is needed by:\n", name)
+			}
+			for _, path := range paths {
+				fmt.Fprintf(env.Stdout, "  %s\n", strings.Join(path, " → "))
+			}
+			return nil
+		}
+	},
+}
+
+var graphCommand = &Command{
+	Name:    "graph",
+	Args:    "[name|glob ...]",
+	Summary: "Print the dependency graph as DOT or Mermaid",
+	Help: `Print the installed dependency graph for Graphviz (dot -Tsvg) or Mermaid.
+Name and glob arguments restrict the graph to the matching packages and
+their dependencies.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		format := fs.String("format", "dot", "graph format: dot, mermaid")
+		return func(env *Env, args []string) error {
+			write := brewls.WriteDOT
+			switch *format {
+			case "dot":
+			case "mermaid":
+				write = brewls.WriteMermaid
+			default:
+				return usageErrorf("unknown graph format %q (expected dot or mermaid)", *format)
+			}
+			selector, err := brewls.NewSelector(args, nil, true)
+			if err != nil {
+				return usageErrorf("invalid package selection: %v", err)
+			}
+
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+			if err := selector.Restrict(info); err != nil {
+				return err
+			}
+			return write(env.Stdout, info)
+		}
+	},
+}
+
+// findFormula resolves a formula by name or full name.
+func findFormula(info *brewls.BrewInfo, name string) (string, bool) {
+	for _, f := range info.Formulae {
+		if f.Name == name || f.FullName == name {
+			return f.Name, true
+		}
+	}
+	return "", false
+}
+
+// findPackage resolves a formula by name or full name, or a cask by token,
+// and reports whether it was installed on request.
+func findPackage(info *brewls.BrewInfo, name string) (string, bool, bool) {
+	for _, f := range info.Formulae {
+		if f.Name == name || f.FullName == name {
+			onRequest := len(f.Installed) > 0 && f.Installed[len(f.Installed)-1].InstalledOnRequest
+			return f.Name, onRequest, true
+		}
+	}
+	for _, c := range info.Casks {
+		if c.Token == name {
+			return c.Token, true, true
+		}
+	}
+	return "", false, false
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+// recordHistory appends the changes in info to the history file.
+func recordHistory(info *brewls.BrewInfo) ([]brewls.HistoryEvent, error) {
+	path, err := brewls.HistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	return brewls.RecordHistory(path, info, now().UTC().Truncate(time.Second))
+}
+
+var historyCommand = &Command{
+	Name:    "history",
+	Args:    "[name] | record",
+	Summary: "Show when packages were installed, upgraded or removed",
+	Help: `Show a timeline of installs, upgrades (old → new version), removals and
+changes between installed on request and installed as a dependency, for every
+package or only the named one. brewls records these in a JSON lines file under
+$XDG_DATA_HOME/brewls (or ~/.local/share/brewls) whenever it runs brew on a
+terminal. Scripts record with --feature record-history, and nothing is
+recorded with --feature -record-history.
+
+'brewls history record' only records, for a login hook or a brew wrapper.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		asJSON := fs.Bool("json", false, "print the events as JSON")
+		return func(env *Env, args []string) error {
+			if len(args) > 1 {
+				return usageErrorf("history takes at most one package name")
+			}
+			if len(args) == 1 && args[0] == "record" {
+				info, err := loadBrewInfo()
+				if err != nil {
+					return err
+				}
+				events, err := recordHistory(info)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(env.Stdout, "Recorded %d change(s).\n", len(events))
+				return nil
+			}
+
+			path, err := brewls.HistoryPath()
+			if err != nil {
+				return err
+			}
+			events, err := brewls.LoadHistory(path)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				events = slices.DeleteFunc(events, func(e brewls.HistoryEvent) bool { return e.Name != args[0] })
+			}
+			slices.SortStableFunc(events, func(a, b brewls.HistoryEvent) int { return a.Time.Compare(b.Time) })
+
+			if *asJSON {
+				if events == nil {
+					events = []brewls.HistoryEvent{}
+				}
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(events)
+			}
+			if len(events) == 0 {
+				if len(args) == 1 {
+					fmt.Fprintf(env.Stdout, "No history for %s.\n", args[0])
+				} else {
+					fmt.Fprintln(env.Stdout, "No history recorded yet; run brewls history record, or any brewls listing on a terminal or with --feature record-history.")
+				}
+				return nil
+			}
+			return brewls.WriteHistory(env.Stdout, events)
+		}
+	},
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"brewls/internal/brewls"
+)
+
+var licensesCommand = &Command{
+	Name:    "licenses",
+	Summary: "Group installed packages by license and check them against a policy",
+	Help: `Group the installed packages by SPDX license expression, and list the
+copyleft licenses each root package brings in with everything it depends on.
+Strong copyleft (GPL, AGPL, ...) and weak copyleft (LGPL, MPL, ...) are
+marked; of "a OR b" the weaker counts, of "a AND b" the stronger.
+
+--allow and --deny take SPDX identifiers or globs such as GPL-*, and replace
+the "licenses" allow and deny lists of the config file. With an allow list,
+every package needs an allowed license, so packages brew reports no license
+for, which includes all casks, are violations too. Exits with status 3 when
+a package violates the policy, so it can check a machine image in CI.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		var allow, deny stringList
+		fs.Var(&allow, "allow", "allowed licenses, comma-separated (repeatable)")
+		fs.Var(&deny, "deny", "denied licenses, comma-separated (repeatable)")
+		asJSON := fs.Bool("json", false, "print the report as JSON")
+		return func(env *Env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("licenses takes no arguments")
+			}
+			policy := env.Config.Licenses
+			if len(allow) > 0 {
+				policy.Allow = splitLicenses(allow)
+			}
+			if len(deny) > 0 {
+				policy.Deny = splitLicenses(deny)
+			}
+			if err := policy.Validate(); err != nil {
+				return usageErrorf("%v", err)
+			}
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+			report := brewls.NewLicenseReport(info, policy)
+
+			if *asJSON {
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(report)
+			} else {
+				err = brewls.WriteLicenseReport(env.Stdout, report)
+			}
+			if err != nil {
+				return err
+			}
+			if len(report.Violations) > 0 {
+				fmt.Fprintf(env.Stderr, "brewls licenses: %d package(s) violate the license policy\n", len(report.Violations))
+				return exitStatus(ExitProblems)
+			}
+			return nil
+		}
+	},
+}
+
+// splitLicenses flattens comma-separated flag values, dropping empty entries.
+func splitLicenses(values []string) []string {
+	var licenses []string
+	for _, value := range values {
+		for license := range strings.SplitSeq(value, ",") {
+			if license = strings.TrimSpace(license); license != "" {
+				licenses = append(licenses, license)
+			}
+		}
+	}
+	return licenses
+}
@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+// listFlags holds the flags shared by list and export that shape a listing.
+// Settings that the config file can also provide are read back from fs by
+// Env.resolve, so only flags the user actually set take precedence.
+type listFlags struct {
+	fs        *flag.FlagSet
+	format    string // Set by export from its argument; list resolves --format
+	jsonLines bool
+	namesOnly bool
+	rootsOnly bool
+	null      bool
+	regexps   stringList
+	withDeps  bool
+	typ       string
+	orphans   bool
+	template  string
+	tmplFile  string
+
+	allRequested bool // export brewfile
+	comments     bool // export brewfile
+}
+
+// addListFlags registers the listing flags on fs. The format flags are only
+// added for list; export takes the format as an argument instead.
+func addListFlags(fs *flag.FlagSet, withFormat bool) *listFlags {
+	lf := &listFlags{fs: fs}
+	if withFormat {
+		fs.String("format", brewls.FormatTable, "output format: "+strings.Join(brewls.Formats(), ", "))
+		fs.BoolVar(&lf.jsonLines, "jsonl", false, "write JSON with one package object per line (implies --format json)")
+		fs.String("color", colorAuto, "color the table: auto (only on a terminal without NO_COLOR), always, never")
+		fs.String("style", brewls.TableStyleDefault, "table style: "+strings.Join(brewls.TableStyles(), ", "))
+		fs.Bool("wide", false, "do not truncate Installed By and other list columns to fit the terminal width")
+		fs.Bool("summary", false, "end the table with a line counting packages, roots, dependencies, orphans and outdated ones")
+		fs.BoolVar(&lf.namesOnly, "1", false, "print bare package names, one per line (same as --names-only)")
+		fs.BoolVar(&lf.namesOnly, "names-only", false, "print bare package names, one per line, without headers or borders")
+		fs.BoolVar(&lf.rootsOnly, "roots-only", false, "print the names of root packages only (implies --names-only)")
+		fs.BoolVar(&lf.null, "0", false, "end names with a NUL byte instead of a newline, for xargs -0 (implies --names-only)")
+		fs.StringVar(&lf.template, "template", "", `print each package with a Go text/template, e.g. '{{.Name}} {{join .InstalledBy ","}}'`)
+		fs.StringVar(&lf.tmplFile, "template-file", "", "like --template, reading the template from this file")
+	}
+	fs.String("list-separator", brewls.DefaultListSeparator, "separator for multi-value cells such as Installed By in csv and tsv output")
+	fs.String("columns", "", "comma-separated columns to show (default from "+brewls.ColumnsEnv+", the config file or name,version,installed_by); available: "+strings.Join(brewls.ColumnKeys(), ", "))
+	fs.String("sort", "", "comma-separated sort keys, prefix with - for descending, e.g. -installed_by_count,name")
+	fs.String("filter", "", `only list packages matching an expression, e.g. 'root && !outdated' or 'name =~ "^python"'`)
+	fs.Var(&lf.regexps, "regex", "only list packages whose name matches this regular expression (repeatable)")
+	fs.BoolVar(&lf.withDeps, "with-deps", false, "also list the transitive dependencies of the selected packages")
+	fs.StringVar(&lf.typ, "type", "", "only list packages of this type: "+brewls.PackageTypeFormula+" or "+brewls.PackageTypeCask)
+	fs.BoolVar(&lf.orphans, "orphans", false, "only list formulae installed as dependencies that nothing needs any more")
+	return lf
+}
+
+// listing is a validated listing request, ready to run against brew data.
+type listing struct {
+	renderer  brewls.Renderer
+	options   brewls.ViewOptions
+	selector  *brewls.Selector
+	needsSize bool // disk usage is shown, sorted or filtered on
+}
+
+// listing merges the flags with env vars and the config file and validates the
+// result and the name or glob patterns before brew runs, so mistakes are
+// reported as usage errors without waiting for the scan.
+func (lf *listFlags) listing(env *Env, patterns []string) (*listing, error) {
+	// from names the source of a setting in error messages when it is not a flag.
+	from := func(s setting) string {
+		if s.Source == sourceFlag {
+			return ""
+		}
+		return " (from " + s.Source + ")"
+	}
+
+	format := env.resolve("format", lf.fs)
+	switch {
+	case lf.format != "":
+		format = setting{Key: format.Key, Value: lf.format, Source: sourceFlag}
+	case lf.namesOnly || lf.rootsOnly || lf.null:
+		if format.Source == sourceFlag || lf.jsonLines {
+			return nil, usageErrorf("--names-only, --roots-only and -0 cannot be combined with --format or --jsonl")
+		}
+		format = setting{Key: format.Key, Value: brewls.FormatNames, Source: sourceFlag}
+	case lf.jsonLines:
+		format = setting{Key: format.Key, Value: brewls.FormatJSON, Source: sourceFlag}
+	}
+	style := env.resolve("style", lf.fs)
+	if !slices.Contains(brewls.TableStyles(), style.Value) {
+		return nil, usageErrorf("unknown table style %q%s (expected one of %s)", style.Value, from(style), strings.Join(brewls.TableStyles(), ", "))
+	}
+	color := env.resolve("color", lf.fs)
+	if lf.format != "" {
+		color.Value = colorNever // export writes files, which should not contain escape sequences
+	} else if !slices.Contains([]string{colorAuto, colorAlways, colorNever}, color.Value) {
+		return nil, usageErrorf("invalid color mode %q%s (expected auto, always or never)", color.Value, from(color))
+	}
+
+	width := 0
+	if wide := env.resolve("wide", lf.fs); lf.format == "" && wide.Value != "true" {
+		width = terminalWidth(env.Stdout)
+	}
+
+	template, err := lf.readTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if template != "" && (format.Source == sourceFlag || lf.jsonLines) {
+		return nil, usageErrorf("--template cannot be combined with --format, --jsonl or --names-only")
+	}
+
+	var timestamp time.Time
+	if format.Value == brewls.FormatCycloneDX || format.Value == brewls.FormatSPDX {
+		if timestamp, err = brewls.SourceDateEpoch(); err != nil {
+			fmt.Fprintf(env.Stderr, "brewls: warning: %v; ignoring it\n", err)
+		}
+		if timestamp.IsZero() && format.Value == brewls.FormatCycloneDX {
+			timestamp = now().UTC().Truncate(time.Second)
+		}
+	}
+	renderer, err := brewls.NewRenderer(format.Value, brewls.RenderOptions{
+		JSONLines:     lf.jsonLines,
+		ListSeparator: env.resolve("list_separator", lf.fs).Value,
+		Color:         useColor(color.Value, env.Stdout),
+		Style:         style.Value,
+		Width:         width,
+		Null:          lf.null,
+		Summary:       env.resolve("summary", lf.fs).Value == "true",
+		AllRequested:  lf.allRequested,
+		Comments:      lf.comments,
+		Timestamp:     timestamp,
+		ToolVersion:   version(),
+		Hostname:      hostname(),
+	})
+	if err != nil {
+		return nil, usageErrorf("%v%s", err, from(format))
+	}
+	if template != "" {
+		if renderer, err = brewls.NewTemplateRenderer(template); err != nil {
+			return nil, usageErrorf("%v", err)
+		}
+	}
+
+	columnSetting := env.resolve("columns", lf.fs)
+	columns, err := brewls.ResolveColumns(brewls.ParseColumnList(columnSetting.Value))
+	if err != nil {
+		return nil, usageErrorf("invalid columns%s: %v", from(columnSetting), err)
+	}
+	for key, limit := range env.widths() {
+		column, ok := brewls.LookupColumn(key)
+		if !ok {
+			return nil, usageErrorf("unknown column %q in widths%s (available: %s)", key, from(limit), strings.Join(brewls.ColumnKeys(), ", "))
+		}
+		n, _ := strconv.Atoi(limit.Value)
+		if n < 0 {
+			return nil, usageErrorf("invalid width %d for column %q%s (must not be negative)", n, key, from(limit))
+		}
+		for i := range columns {
+			if columns[i].Key == column.Key {
+				columns[i].Width = n
+			}
+		}
+	}
+
+	options := brewls.DefaultViewOptions()
+	options.Columns = columns
+	if sortSetting := env.resolve("sort", lf.fs); sortSetting.Value != "" {
+		if options.Sort, err = brewls.ParseSortKeys(sortSetting.Value); err != nil {
+			return nil, usageErrorf("invalid sort%s: %v", from(sortSetting), err)
+		}
+	}
+	if filterSetting := env.resolve("filter", lf.fs); filterSetting.Value != "" {
+		if options.Filter, err = brewls.ParseFilter(filterSetting.Value); err != nil {
+			return nil, usageErrorf("%v%s", err, from(filterSetting))
+		}
+	}
+
+	// The shortcut flags narrow the listing further, on top of any filter.
+	var shortcuts []string
+	switch lf.typ {
+	case "":
+	case brewls.PackageTypeFormula, brewls.PackageTypeCask:
+		shortcuts = append(shortcuts, fmt.Sprintf("type == %q", lf.typ))
+	default:
+		return nil, usageErrorf("invalid type %q (expected %s or %s)", lf.typ, brewls.PackageTypeFormula, brewls.PackageTypeCask)
+	}
+	if lf.orphans {
+		shortcuts = append(shortcuts, "orphan")
+	}
+	if lf.rootsOnly {
+		shortcuts = append(shortcuts, "root")
+	}
+	if len(shortcuts) > 0 {
+		shortcut, err := brewls.ParseFilter(strings.Join(shortcuts, " && "))
+		if err != nil {
+			return nil, err
+		}
+		options.Filter = options.Filter.And(shortcut)
+	}
+
+	selector, err := brewls.NewSelector(patterns, lf.regexps, lf.withDeps)
+	if err != nil {
+		return nil, usageErrorf("invalid package selection: %v", err)
+	}
+
+	needsSize := slices.ContainsFunc(options.Sort, func(k brewls.SortKey) bool { return k.Column.Key == "size" }) ||
+		options.Filter.UsesColumn("size")
+	if t, ok := renderer.(*brewls.TemplateRenderer); ok {
+		// Templates ignore --columns and show only what they refer to.
+		needsSize = needsSize || t.UsesField("SizeBytes")
+	} else {
+		needsSize = needsSize || slices.ContainsFunc(options.Columns, func(c brewls.Column) bool { return c.Key == "size" })
+	}
+	return &listing{renderer: renderer, options: options, selector: selector, needsSize: needsSize}, nil
+}
+
+// readTemplate returns the --template text, or the contents of --template-file.
+func (lf *listFlags) readTemplate() (string, error) {
+	if lf.tmplFile == "" {
+		return lf.template, nil
+	}
+	if lf.template != "" {
+		return "", usageErrorf("--template and --template-file cannot be combined")
+	}
+	data, err := os.ReadFile(lf.tmplFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// view loads the installed packages and builds the view the listing describes.
+func (l *listing) view(env *Env) (*brewls.View, error) {
+	info, err := env.BrewInfo()
+	if err != nil {
+		return nil, err
+	}
+	installation := *info
+	if err := l.selector.Restrict(info); err != nil {
+		return nil, err
+	}
+
+	if l.needsSize {
+		prefixes := env.Config.Prefixes
+		if len(prefixes) == 0 {
+			prefix, err := brewls.BrewPrefix()
+			if err != nil {
+				return nil, fmt.Errorf("failed to locate Homebrew prefix: %w", err)
+			}
+			prefixes = []string{prefix}
+		}
+		brewls.PopulateDiskUsage(info, prefixes...)
+	}
+
+	view := brewls.NewView(info, l.options)
+	view.Installation = &installation
+	return view, nil
+}
+
+func (l *listing) render(env *Env, w io.Writer) error {
+	view, err := l.view(env)
+	if err != nil {
+		return err
+	}
+	if err := l.renderer.Render(view, w); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+var listCommand = &Command{
+	Name:    "list",
+	Args:    "[name|glob ...]",
+	Summary: "List installed packages and what installed them",
+	Help: `List installed formulae and casks with the packages that depend on them.
+Name and glob arguments restrict the listing; a package that shares its name
+with a command, such as tree, can be listed with 'brewls list tree'.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		lf := addListFlags(fs, true)
+		return func(env *Env, args []string) error {
+			l, err := lf.listing(env, args)
+			if err != nil {
+				return err
+			}
+			return l.render(env, env.Stdout)
+		}
+	},
+}
+
+var exportCommand = &Command{
+	Name:    "export",
+	Args:    "<format> [name|glob ...]",
+	Summary: "Write the listing in an output format to a file",
+	Help: `Write the listing in the given output format (` + strings.Join(brewls.Formats(), ", ") + `)
+to standard output, or to the file named by -o.
+
+The brewfile format writes a Brewfile for brew bundle with the root packages
+and the taps they come from, so a machine can be rebuilt from what was
+actually asked for rather than everything brew bundle dump would list.
+
+The cyclonedx and spdx formats write a software bill of materials, as
+CycloneDX 1.5 or SPDX 2.3 JSON, with every package, its license and package
+URL, and the dependencies between them. CycloneDX records the current time
+and SPDX the latest install time of the packages, so SPDX exports of an
+unchanged installation are identical byte for byte. SOURCE_DATE_EPOCH, when
+set, overrides both.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		lf := addListFlags(fs, false)
+		output := fs.String("o", "", "write to this file instead of standard output")
+		fs.BoolVar(&lf.allRequested, "all-requested", false, "brewfile: include every formula installed on request, not only roots")
+		fs.BoolVar(&lf.comments, "comments", false, "brewfile: note the installed packages each entry pulls in")
+		return func(env *Env, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("missing export format (expected one of %s)", strings.Join(brewls.Formats(), ", "))
+			}
+			lf.format = args[0]
+			l, err := lf.listing(env, args[1:])
+			if err != nil {
+				return err
+			}
+			return writeOutput(env, *output, l.render)
+		}
+	},
+}
+
+// writeOutput calls write with standard output, or with the named file when
+// path is set and not "-".
+func writeOutput(env *Env, path string, write func(*Env, io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(env, env.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(env, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
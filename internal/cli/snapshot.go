@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"brewls/internal/brewls"
+)
+
+// brewVersion, now and hostname are variables so tests can fix the metadata
+// of snapshots and SBOMs.
+var (
+	brewVersion = brewls.BrewVersion
+	now         = time.Now
+	hostname    = func() string {
+		name, _ := os.Hostname()
+		return name
+	}
+)
+
+var snapshotCommand = &Command{
+	Name:    "snapshot",
+	Args:    "save [name] | list",
+	Summary: "Save the installed state to compare later with diff",
+	Help: `'brewls snapshot save' records every installed package with its version,
+root status and dependencies, plus the time, hostname and brew version, in
+the snapshot directory ($XDG_DATA_HOME/brewls/snapshots or
+~/.local/share/brewls/snapshots). The name defaults to the current date and
+time. 'brewls snapshot list' prints the saved snapshots.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		force := fs.Bool("force", false, "replace a snapshot with the same name")
+		return func(env *Env, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("snapshot needs one of: save, list")
+			}
+			dir, err := brewls.SnapshotDir()
+			if err != nil {
+				return err
+			}
+			switch args[0] {
+			case "save":
+				if len(args) > 2 {
+					return usageErrorf("snapshot save takes at most one name")
+				}
+				// Check the name before running brew, which takes a while.
+				if len(args) == 2 {
+					if err := brewls.ValidSnapshotName(args[1]); err != nil {
+						return usageErrorf("%v", err)
+					}
+				}
+				snapshot, err := currentSnapshot(env)
+				if err != nil {
+					return err
+				}
+				snapshot.Name = snapshot.CreatedAt.Format("20060102-150405")
+				if len(args) == 2 {
+					snapshot.Name = args[1]
+				}
+				path, err := brewls.SaveSnapshot(dir, snapshot, *force)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(env.Stdout, "Saved snapshot %s with %d packages to %s\n", snapshot.Name, len(snapshot.Packages), path)
+				return nil
+			case "list":
+				names, err := brewls.SnapshotNames(dir)
+				if err != nil {
+					return err
+				}
+				for _, name := range names {
+					fmt.Fprintln(env.Stdout, name)
+				}
+				return nil
+			default:
+				return usageErrorf("unknown snapshot command %q (expected save or list)", args[0])
+			}
+		}
+	},
+}
+
+// currentSnapshot captures the installed packages with this machine's metadata.
+// A brew version that cannot be determined is left empty.
+func currentSnapshot(env *Env) (*brewls.Snapshot, error) {
+	info, err := env.BrewInfo()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := brewls.NewSnapshot(info)
+	snapshot.CreatedAt = now().UTC().Truncate(time.Second)
+	snapshot.Hostname = hostname()
+	snapshot.BrewVersion, _ = brewVersion()
+	snapshot.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	return snapshot, nil
+}
+
+var diffCommand = &Command{
+	Name:    "diff",
+	Args:    "<a> [b]",
+	Summary: "Compare two snapshots, or a snapshot with the installed state",
+	Help: `Show packages added and removed between snapshot a and snapshot b, version
+changes, packages that became or stopped being roots, and dependency edges
+added or removed between packages in both. Without b, a is compared with what
+is installed now. Snapshots are named as saved by 'brewls snapshot save', or
+given as paths to snapshot files, which may come from another machine.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		asJSON := fs.Bool("json", false, "print the differences as JSON")
+		return func(env *Env, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return usageErrorf("diff needs one or two snapshots")
+			}
+			a, err := loadSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			var b *brewls.Snapshot
+			if len(args) == 2 {
+				b, err = loadSnapshot(args[1])
+			} else {
+				b, err = currentSnapshot(env)
+				if b != nil {
+					b.Name = "installed"
+				}
+			}
+			if err != nil {
+				return err
+			}
+			diff := brewls.DiffSnapshots(a, b)
+
+			if *asJSON {
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(diff)
+			}
+			fmt.Fprintf(env.Stdout, "%s → %s\n", describeSnapshot(a), describeSnapshot(b))
+			if diff.Empty() {
+				fmt.Fprintln(env.Stdout, "No changes.")
+				return nil
+			}
+			return brewls.WriteSnapshotDiff(env.Stdout, diff)
+		}
+	},
+}
+
+// loadSnapshot reads a snapshot by name from the snapshot directory, or from a
+// file when arg is a path.
+func loadSnapshot(arg string) (*brewls.Snapshot, error) {
+	path := arg
+	if !strings.ContainsRune(arg, filepath.Separator) && !strings.ContainsRune(arg, '/') && !strings.HasSuffix(arg, ".json") {
+		dir, err := brewls.SnapshotDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, arg+".json")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil, usageErrorf("no snapshot named %q in %s (see brewls snapshot list)", arg, dir)
+		}
+	}
+	return brewls.LoadSnapshot(path)
+}
+
+func describeSnapshot(s *brewls.Snapshot) string {
+	var details []string
+	if !s.CreatedAt.IsZero() {
+		details = append(details, s.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if s.Hostname != "" {
+		details = append(details, s.Hostname)
+	}
+	if len(details) == 0 {
+		return s.Name
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, strings.Join(details, ", "))
+}
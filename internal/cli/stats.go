@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+
+	"brewls/internal/brewls"
+)
+
+var statsCommand = &Command{
+	Name:    "stats",
+	Summary: "Show counts, dependency depth and the most depended-on packages",
+	Help: `Show how many formulae and casks are installed, how many are roots,
+dependencies of other packages or orphans nothing needs any more, how many
+are outdated, how deep the dependency trees below the roots go, and the
+packages with the most dependents, counting indirect ones.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		asJSON := fs.Bool("json", false, "print the statistics as JSON")
+		top := fs.Int("top", 10, "number of most depended-on packages to show")
+		return func(env *Env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("stats takes no arguments")
+			}
+			if *top < 0 {
+				return usageErrorf("invalid --top %d (must not be negative)", *top)
+			}
+			info, err := env.BrewInfo()
+			if err != nil {
+				return err
+			}
+			stats := brewls.ComputeStats(info, *top)
+
+			if *asJSON {
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(stats)
+			}
+
+			w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Formulae:\t%d\n", stats.Formulae)
+			fmt.Fprintf(w, "Casks:\t%d\n", stats.Casks)
+			fmt.Fprintf(w, "Roots:\t%d\n", stats.Roots)
+			fmt.Fprintf(w, "Dependencies:\t%d\n", stats.Dependencies)
+			fmt.Fprintf(w, "Orphans:\t%d\n", stats.Orphans)
+			fmt.Fprintf(w, "Outdated:\t%d\n", stats.Outdated)
+			fmt.Fprintf(w, "Average depth:\t%.1f\n", stats.AverageDepth)
+			fmt.Fprintf(w, "Max depth:\t%d\n", stats.MaxDepth)
+			if len(stats.MostDependedOn) > 0 {
+				fmt.Fprintln(w, "\nMost depended on:\t")
+				for _, c := range stats.MostDependedOn {
+					fmt.Fprintf(w, "  %s\t%d\n", c.Name, c.Dependents)
+				}
+			}
+			return w.Flush()
+		}
+	},
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// Values accepted by --color.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// isTerminal reports whether w is a character device such as a terminal,
+// as opposed to a pipe or a regular file.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// interactive reports whether output written to w is read by a person; tests
+// replace it.
+var interactive = isTerminal
+
+// useColor resolves a --color mode for output written to w. In auto mode,
+// color is used for terminals unless NO_COLOR is set (https://no-color.org)
+// or TERM is "dumb"; always and never ignore both.
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// terminalWidth returns the width tables written to w are fitted to: COLUMNS
+// when set, otherwise the size the terminal reports. Output that is not a
+// terminal, such as a pipe, has no width and returns 0.
+func terminalWidth(w io.Writer) int {
+	if !isTerminal(w) {
+		return 0
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return terminalSize(w.(*os.File))
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || linux)
+
+package cli
+
+import "os"
+
+// terminalSize returns 0: brewls does not query the terminal size on this
+// platform, so tables are only fitted when COLUMNS is set.
+func terminalSize(*os.File) int {
+	return 0
+}
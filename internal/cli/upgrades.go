@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"brewls/internal/brewls"
+)
+
+var upgradesCommand = &Command{
+	Name:    "upgrades",
+	Args:    "[a [b]]",
+	Summary: "Classify pending or past upgrades as major, minor, patch or revision",
+	Help: `Without arguments, list the outdated packages with the version brew would
+upgrade them to. With snapshots, list the version changes from snapshot a to
+snapshot b, or to what is installed now when b is left out, like diff.
+
+Each upgrade is classified by the first version component that changed
+(major, minor, patch, or revision for "_N" rebuilds; date-based and
+non-numeric versions are grouped separately) and the report is grouped by
+kind, major upgrades first with every installed package they may affect.`,
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		asJSON := fs.Bool("json", false, "print the upgrades as JSON")
+		return func(env *Env, args []string) error {
+			var upgrades []brewls.Upgrade
+			switch len(args) {
+			case 0:
+				info, err := env.BrewInfo()
+				if err != nil {
+					return err
+				}
+				upgrades = brewls.PendingUpgrades(info)
+			case 1, 2:
+				a, err := loadSnapshot(args[0])
+				if err != nil {
+					return err
+				}
+				var b *brewls.Snapshot
+				if len(args) == 2 {
+					b, err = loadSnapshot(args[1])
+				} else {
+					b, err = currentSnapshot(env)
+				}
+				if err != nil {
+					return err
+				}
+				upgrades = brewls.SnapshotUpgrades(a, b)
+			default:
+				return usageErrorf("upgrades takes at most two snapshots")
+			}
+
+			if *asJSON {
+				encoder := json.NewEncoder(env.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(upgrades)
+			}
+			if len(upgrades) == 0 {
+				if len(args) == 0 {
+					fmt.Fprintln(env.Stdout, "Everything is up to date.")
+				} else {
+					fmt.Fprintln(env.Stdout, "No version changes.")
+				}
+				return nil
+			}
+			return brewls.WriteUpgradeReport(env.Stdout, upgrades)
+		}
+	},
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version overrides the version reported by brewls when set at link time:
+//
+//	go build -ldflags "-X brewls/internal/cli.Version=v1.2.3" ./cmd/brewls
+//
+// Otherwise the module version or VCS revision from the build info is used.
+var Version string
+
+// readBuildInfo is a variable so tests can supply build info.
+var readBuildInfo = debug.ReadBuildInfo
+
+// version returns the brewls version, e.g. "v1.2.3" or "devel (a1b2c3d4e5f6, modified)".
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := readBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision == "" {
+		return "devel"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified == "true" {
+		return fmt.Sprintf("devel (%s, modified)", revision)
+	}
+	return fmt.Sprintf("devel (%s)", revision)
+}
+
+func versionLine() string {
+	return fmt.Sprintf("brewls %s (%s %s/%s)", version(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+var versionCommand = &Command{
+	Name:    "version",
+	Summary: "Print the brewls version",
+	Help:    "Print the brewls version and the Go toolchain and platform it was built for.",
+	Setup: func(fs *flag.FlagSet) func(*Env, []string) error {
+		return func(env *Env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("version takes no arguments")
+			}
+			fmt.Fprintln(env.Stdout, versionLine())
+			return nil
+		}
+	},
+}
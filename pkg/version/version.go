@@ -0,0 +1,352 @@
+// Package version parses Homebrew version strings and orders them the way
+// Homebrew does, so brewls can tell which of two versions is newer and how big
+// the step between them is.
+//
+// A Homebrew version is a release such as "3.12.4", optionally followed by a
+// "_N" revision counting rebuilds of that release ("3.3.0_1"). Casks may add a
+// ",build" suffix ("4.28.0,139208") or be versioned "latest", and formulae
+// built from their development branch are versioned "HEAD-<sha>".
+package version
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Bump describes the change from one version to another; see Classify.
+type Bump string
+
+// Kinds of change returned by Classify, from most to least likely to break
+// dependents.
+const (
+	None      Bump = ""
+	Major     Bump = "major"
+	Minor     Bump = "minor"
+	Patch     Bump = "patch"
+	Revision  Bump = "revision"  // only the "_N" rebuild suffix changed
+	Date      Bump = "date"      // date-based versions such as 20240101 or 2024.01.15
+	Other     Bump = "other"     // HEAD, latest, or versions that do not start with a number
+	Downgrade Bump = "downgrade" // the new version is older
+)
+
+// Bumps lists every Bump but None, most disruptive first.
+var Bumps = []Bump{Major, Minor, Patch, Revision, Date, Other, Downgrade}
+
+// Version is a parsed Homebrew version. The zero Version is the empty string.
+type Version struct {
+	raw      string
+	release  []token // release components, e.g. 3, 12, 4
+	build    []token // cask build after the first ","
+	revision int
+	head     string // sha after "HEAD-", or "HEAD" for a bare HEAD version
+	latest   bool
+}
+
+// token is a run of digits or of letters in a version string.
+type token struct {
+	text    string
+	numeric bool
+	pre     int // rank of a pre-release word, 0 for other tokens
+}
+
+// preReleaseRanks orders the words that mark a version as coming before the
+// release it names, e.g. "1.0rc1" comes before "1.0". "a" and "b" only count
+// when a number follows ("3.13.0a1"); otherwise they are patch letters
+// ("1.0.2a").
+var preReleaseRanks = map[string]int{
+	"dev": 1, "snapshot": 1, "alpha": 2, "a": 2, "beta": 3, "b": 3, "pre": 4, "preview": 4, "rc": 5,
+}
+
+// Parse parses a Homebrew version string. It never fails: whatever is not
+// recognised is compared as runs of digits and letters.
+func Parse(s string) Version {
+	v := Version{raw: s}
+	var rest string
+	rest, v.revision = splitRevision(strings.TrimSpace(s))
+
+	switch {
+	case strings.EqualFold(rest, "latest"):
+		v.latest = true
+		return v
+	case rest == "HEAD":
+		v.head = "HEAD"
+		return v
+	case strings.HasPrefix(rest, "HEAD-"):
+		v.head = rest[len("HEAD-"):]
+		return v
+	}
+
+	release, build, _ := strings.Cut(rest, ",")
+	v.release = tokenize(release)
+	v.build = tokenize(build)
+	return v
+}
+
+// splitRevision separates a "_N" revision suffix, so "3.3.0_1" becomes
+// "3.3.0" and 1. Versions without one have revision 0.
+func splitRevision(s string) (string, int) {
+	i := strings.LastIndex(s, "_")
+	if i <= 0 {
+		return s, 0
+	}
+	revision, err := strconv.Atoi(s[i+1:])
+	if err != nil || revision < 0 {
+		return s, 0
+	}
+	return s[:i], revision
+}
+
+// tokenize splits s into runs of digits and of letters; any other character
+// separates tokens. Letters are lowercased.
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	numeric := false
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{text: strings.ToLower(s[start:end]), numeric: numeric})
+			start = -1
+		}
+	}
+	for i, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			if start >= 0 && !numeric {
+				flush(i)
+			}
+			if start < 0 {
+				start, numeric = i, true
+			}
+		case unicode.IsLetter(r):
+			if start >= 0 && numeric {
+				flush(i)
+			}
+			if start < 0 {
+				start, numeric = i, false
+			}
+		default:
+			flush(i)
+		}
+	}
+	flush(len(s))
+
+	for i, t := range tokens {
+		if t.numeric {
+			continue
+		}
+		if len(t.text) == 1 && (i+1 == len(tokens) || !tokens[i+1].numeric) {
+			continue
+		}
+		tokens[i].pre = preReleaseRanks[t.text]
+	}
+	return tokens
+}
+
+// String returns the version as it was parsed.
+func (v Version) String() string { return v.raw }
+
+// Revision returns the "_N" rebuild counter, 0 when there is none.
+func (v Version) Revision() int { return v.revision }
+
+// WithoutRevision returns the version with its revision dropped, so
+// "3.3.0_1" becomes "3.3.0".
+func (v Version) WithoutRevision() Version {
+	if v.revision == 0 {
+		return v
+	}
+	v.raw, _ = splitRevision(v.raw)
+	v.revision = 0
+	return v
+}
+
+// IsHead reports whether the version is a build of the development branch.
+func (v Version) IsHead() bool { return v.head != "" }
+
+// IsLatest reports whether the version is a cask's "latest", which always
+// installs the newest release.
+func (v Version) IsLatest() bool { return v.latest }
+
+// IsDate reports whether the release is a date, written as YYYYMMDD or as a
+// year followed by more numbers, e.g. 2024.01.15.
+func (v Version) IsDate() bool {
+	if len(v.release) == 0 || !v.release[0].numeric {
+		return false
+	}
+	first := v.release[0].text
+	if len(first) == 8 && (strings.HasPrefix(first, "19") || strings.HasPrefix(first, "20")) {
+		return true
+	}
+	year, _ := strconv.Atoi(first)
+	return len(first) == 4 && year >= 1990 && year <= 2100 && len(v.release) > 1 && v.release[1].numeric
+}
+
+// numeric reports whether the release starts with a number, which the
+// major, minor and patch classification needs.
+func (v Version) numeric() bool {
+	return !v.latest && v.head == "" && len(v.release) > 0 && v.release[0].numeric
+}
+
+// Compare orders v against other, returning -1, 0 or 1. Numbers compare
+// numerically and trailing zeros are ignored ("1.10" > "1.9", "1.0" ==
+// "1.0.0"); pre-release words come before the release ("1.0rc1" < "1.0");
+// a letter suffix comes after the plain number ("1.1.1w" > "1.1.1"); then the
+// cask build and the revision break ties. HEAD and latest are newer than any
+// release, and two HEAD builds only differ by revision.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.latest || other.latest:
+		if v.latest != other.latest {
+			return boolOrder(v.latest)
+		}
+	case v.head != "" || other.head != "":
+		if (v.head != "") != (other.head != "") {
+			return boolOrder(v.head != "")
+		}
+	default:
+		if c, _ := firstDifference(v.release, other.release); c != 0 {
+			return c
+		}
+		if c, _ := firstDifference(v.build, other.build); c != 0 {
+			return c
+		}
+	}
+	return compareInts(v.revision, other.revision)
+}
+
+// Compare parses and orders two version strings; see Version.Compare.
+func Compare(a, b string) int {
+	return Parse(a).Compare(Parse(b))
+}
+
+// Classify describes the change from one version to another. For releases
+// that start with a number, the first release component that differs makes
+// the change major, minor or patch; a different cask build is a patch, and a
+// different revision alone a Revision. Date releases are Date. Changes
+// to or from HEAD or latest, and between versions without a leading number,
+// are Other. Equal versions are None.
+func Classify(from, to string) Bump {
+	f, t := Parse(from), Parse(to)
+	switch {
+	case f.raw == t.raw:
+		return None
+	case !f.numeric() || !t.numeric():
+		if f.WithoutRevision().raw == t.WithoutRevision().raw {
+			return revisionBump(f, t)
+		}
+		return Other
+	}
+
+	c, index := firstDifference(t.release, f.release)
+	switch {
+	case c < 0:
+		return Downgrade
+	case c == 0:
+		if c, _ := firstDifference(t.build, f.build); c != 0 {
+			if c < 0 {
+				return Downgrade
+			}
+			return Patch
+		}
+		return revisionBump(f, t)
+	case f.IsDate() && t.IsDate():
+		return Date
+	case index == 0:
+		return Major
+	case index == 1:
+		return Minor
+	}
+	return Patch
+}
+
+// revisionBump classifies versions that differ at most in their revision.
+func revisionBump(from, to Version) Bump {
+	switch c := compareInts(to.revision, from.revision); {
+	case c > 0:
+		return Revision
+	case c < 0:
+		return Downgrade
+	}
+	return None
+}
+
+// firstDifference compares two token lists and returns the result with the
+// index of the first token that differs, or -1 when they are equal.
+func firstDifference(a, b []token) (int, int) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var c int
+		switch {
+		case i >= len(a):
+			c = -trailingSign(b[i])
+		case i >= len(b):
+			c = trailingSign(a[i])
+		default:
+			c = compareTokens(a[i], b[i])
+		}
+		if c != 0 {
+			return c, i
+		}
+	}
+	return 0, -1
+}
+
+// trailingSign is how a token that only one of two versions has orders that
+// version against the other: zeros do not count ("1.0" equals "1.0.0"),
+// pre-release words make it older and anything else newer.
+func trailingSign(t token) int {
+	if t.numeric {
+		if strings.Trim(t.text, "0") == "" {
+			return 0
+		}
+		return 1
+	}
+	if t.pre > 0 {
+		return -1
+	}
+	return 1
+}
+
+func compareTokens(a, b token) int {
+	switch {
+	case a.numeric && b.numeric:
+		return compareNumbers(a.text, b.text)
+	case a.pre > 0 && b.pre > 0:
+		return compareInts(a.pre, b.pre)
+	case a.pre > 0:
+		return -1
+	case b.pre > 0:
+		return 1
+	case a.numeric != b.numeric:
+		// A letter after a number is a patch letter ("1.1.1w"), which a
+		// further number outranks.
+		return boolOrder(a.numeric)
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+// compareNumbers compares digit strings of any length numerically.
+func compareNumbers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return compareInts(len(a), len(b))
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// boolOrder is 1 when the first of two values that differ is the true one.
+func boolOrder(first bool) int {
+	if first {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,135 @@
+package version_test
+
+import (
+	"testing"
+
+	"brewls/pkg/version"
+)
+
+// Versions taken from Homebrew formulae and casks, oldest first within each
+// group. Every version must compare older than all that follow it in its group.
+var orderedGroups = [][]string{
+	{"1.1.1v", "1.1.1w", "3.0.0", "3.3.0", "3.3.0_1", "3.3.1", "3.3.2"},                     // openssl
+	{"3.9.19", "3.11.9", "3.12.0a1", "3.12.0b4", "3.12.0rc2", "3.12.0", "3.12.4", "3.13.0"}, // python
+	{"9.6p1", "9.7p1", "9.8p1"},                                 // openssh
+	{"73.2", "74.2", "74.2_1", "75.1"},                          // icu4c
+	{"1.0-alpha", "1.0-alpha.2", "1.0-beta", "1.0-rc.1", "1.0"}, // pre-release words
+	{"1.0.dev1", "1.0.pre1", "1.0.preview2", "1.0"},
+	{"2.9", "2.10", "2.10.1", "2.100"},                                   // numeric, not lexical
+	{"0.9.8zg", "0.9.8zh", "1.0.0"},                                      // patch letters
+	{"2023.12.30", "2024.01.15", "2024.1.16", "2024.10.2"},               // dates with dots
+	{"20231231", "20240101", "20240101_1"},                               // dates without
+	{"10.02.1", "10.03.0", "10.03.1"},                                    // leading zeros
+	{"4.27.2,137060", "4.28.0,139021", "4.28.0,139208", "4.29.0,145265"}, // cask builds
+	{"125.0", "125.0.1", "126.0b3", "126.0"},                             // firefox
+	{"r99", "r100", "r101"},                                              // svn revisions
+	{"1.2.3", "HEAD-abc1234", "HEAD-abc1234_1"},
+	{"125.0", "latest", "latest_1"},
+}
+
+func TestCompareOrder(t *testing.T) {
+	for _, group := range orderedGroups {
+		for i := range group {
+			for j := range group {
+				expected := 0
+				if i < j {
+					expected = -1
+				} else if i > j {
+					expected = 1
+				}
+				if got := version.Compare(group[i], group[j]); got != expected {
+					t.Errorf("Compare(%q, %q) = %d, expected %d", group[i], group[j], got, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	equal := [][2]string{
+		{"1.0", "1.0.0"},
+		{"2.44.0", "2.44"},
+		{"1.0RC1", "1.0rc1"},
+		{"1.0-rc1", "1.0.rc.1"},
+		{"007", "7"},
+		{"HEAD-abc1234", "HEAD-def5678"},
+		{"latest", "latest"},
+		{"", ""},
+	}
+	for _, pair := range equal {
+		if got := version.Compare(pair[0], pair[1]); got != 0 {
+			t.Errorf("Compare(%q, %q) = %d, expected 0", pair[0], pair[1], got)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		from, to string
+		expected version.Bump
+	}{
+		{"20.11.0", "22.3.0", version.Major},
+		{"1.1.1w", "3.0.0", version.Major},
+		{"0.9.9", "1.0.0", version.Major},
+		{"2.44.0", "2.45.0", version.Minor},
+		{"3.11.9", "3.12.4", version.Minor},
+		{"9.7p1", "9.8p1", version.Minor},
+		{"73.2", "74.2", version.Major},
+		{"3.12.3", "3.12.4", version.Patch},
+		{"1.2.3.4", "1.2.3.5", version.Patch},
+		{"1.1.1v", "1.1.1w", version.Patch},
+		{"9.8p1", "9.8p2", version.Patch},
+		{"3.12.0rc2", "3.12.0", version.Patch},
+		{"4.28.0,139021", "4.28.0,139208", version.Patch},
+		{"4.28.0,139208", "4.29.0,145265", version.Minor},
+		{"3.3.0", "3.3.0_1", version.Revision},
+		{"74.2_1", "74.2_2", version.Revision},
+		{"HEAD-abc1234", "HEAD-abc1234_1", version.Revision},
+		{"latest", "latest_1", version.Revision},
+		{"20240101", "20240315", version.Date},
+		{"2024.01.15", "2025.02.01", version.Date},
+		{"2024.01.15", "2024.01.16", version.Date},
+		{"1.2.3", "HEAD-abc1234", version.Other},
+		{"HEAD-abc1234", "HEAD-def5678", version.Other},
+		{"125.0", "latest", version.Other},
+		{"a1b2c3d", "e4f5a6b", version.Other},
+		{"r100", "r101", version.Other},
+		{"2.45.0", "2.44.0", version.Downgrade},
+		{"3.3.0_1", "3.3.0", version.Downgrade},
+		{"4.28.0,139208", "4.28.0,139021", version.Downgrade},
+		{"2.44.0", "2.44.0", version.None},
+		{"1.0", "1.0.0", version.None},
+	}
+	for _, tt := range tests {
+		if got := version.Classify(tt.from, tt.to); got != tt.expected {
+			t.Errorf("Classify(%q, %q) = %q, expected %q", tt.from, tt.to, got, tt.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	v := version.Parse("3.3.0_1")
+	if v.String() != "3.3.0_1" || v.Revision() != 1 || v.WithoutRevision().String() != "3.3.0" {
+		t.Errorf("Unexpected parse of 3.3.0_1: %q revision %d", v.String(), v.Revision())
+	}
+	if v := version.Parse("HEAD-1a2b3c4_2"); !v.IsHead() || v.Revision() != 2 || v.IsLatest() {
+		t.Errorf("Expected a HEAD version with revision 2, got %+v", v)
+	}
+	if v := version.Parse("latest"); !v.IsLatest() || v.IsHead() {
+		t.Errorf("Expected latest, got %+v", v)
+	}
+	for _, s := range []string{"20240101", "2024.01.15", "1999.12"} {
+		if !version.Parse(s).IsDate() {
+			t.Errorf("Expected %q to be a date version", s)
+		}
+	}
+	for _, s := range []string{"2.44.0", "2024", "12345678", "r20240101"} {
+		if version.Parse(s).IsDate() {
+			t.Errorf("Expected %q not to be a date version", s)
+		}
+	}
+	// Underscores that are not a number are part of the version.
+	if v := version.Parse("1_beta"); v.Revision() != 0 || v.String() != "1_beta" {
+		t.Errorf("Expected no revision in 1_beta, got %d", v.Revision())
+	}
+}
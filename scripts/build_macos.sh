@@ -12,7 +12,7 @@ build_target() {
   local tarball="${out}.tar.gz"
 
   echo "Building darwin/${arch}..."
-  GOOS=darwin GOARCH="$arch" go build -o "$out" ./cmd/brewls
+  GOOS=darwin GOARCH="$arch" go build -ldflags "-X brewls/internal/cli.Version=${VERSION}" -o "$out" ./cmd/brewls
   tar -czf "$tarball" -C "$DIST_DIR" "brewls_${VERSION}_darwin_${arch}"
   shasum -a 256 "$tarball" > "${tarball}.sha256"
   rm -f "$out"